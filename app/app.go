@@ -0,0 +1,55 @@
+// Package app serves Go apps of type "go" in-process, without
+// the child process and loopback hop of the relay. The app
+// registers its handler from main:
+//
+//	func main() {
+//	  app.Handle(mux)
+//	}
+package app
+
+import (
+	"net"
+	"net/http"
+	"os"
+
+	"github.com/apex/log"
+
+	"github.com/apex/up"
+	"github.com/apex/up/internal/proxy"
+)
+
+// Handle serves h. On Lambda h is wrapped with all Up middleware,
+// otherwise it is served on PORT, or UP_SOCKET when present, as
+// the app is relayed by `up start` which provides the middleware.
+func Handle(h http.Handler) {
+	if os.Getenv("AWS_LAMBDA_FUNCTION_NAME") != "" {
+		proxy.Serve(func(*up.Config) (http.Handler, error) {
+			return h, nil
+		})
+		return
+	}
+
+	if err := listenAndServe(h); err != nil {
+		log.Fatalf("error serving: %s", err)
+	}
+}
+
+// listenAndServe serves h on PORT, or UP_SOCKET when present.
+func listenAndServe(h http.Handler) error {
+	network, addr := "tcp", ":"+os.Getenv("PORT")
+
+	if s := os.Getenv("UP_SOCKET"); s != "" {
+		network, addr = "unix", s
+	}
+
+	if addr == ":" {
+		addr = ":3000"
+	}
+
+	l, err := net.Listen(network, addr)
+	if err != nil {
+		return err
+	}
+
+	return http.Serve(l, h)
+}
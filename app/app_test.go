@@ -0,0 +1,40 @@
+package app
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/facebookgo/freeport"
+	"github.com/tj/assert"
+
+	"github.com/apex/up/internal/util"
+)
+
+func TestHandle_relayed(t *testing.T) {
+	port, err := freeport.Get()
+	assert.NoError(t, err, "port")
+
+	os.Setenv("PORT", strconv.Itoa(port))
+	defer os.Unsetenv("PORT")
+
+	go listenAndServe(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, "Hello World")
+	}))
+
+	u := &url.URL{Scheme: "http", Host: fmt.Sprintf("127.0.0.1:%d", port)}
+	assert.NoError(t, util.WaitForListen(u, time.Second), "listen")
+
+	res, err := http.Get(u.String())
+	assert.NoError(t, err, "get")
+	defer res.Body.Close()
+
+	b, err := ioutil.ReadAll(res.Body)
+	assert.NoError(t, err, "read")
+	assert.Equal(t, "Hello World", string(b))
+}
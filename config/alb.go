@@ -0,0 +1,45 @@
+package config
+
+import (
+	"github.com/pkg/errors"
+
+	"github.com/apex/up/internal/validate"
+)
+
+// ALB config.
+type ALB struct {
+	// ListenerArn is the ARN of the Application Load Balancer
+	// listener which forwards requests to the stage.
+	ListenerArn string `json:"listener_arn"`
+
+	// Priority of the listener rule, unique within the listener.
+	Priority int `json:"priority"`
+
+	// Hosts matched by the listener rule.
+	Hosts []string `json:"hosts"`
+
+	// Paths matched by the listener rule, defaulting to all paths.
+	Paths []string `json:"paths"`
+}
+
+// Default implementation.
+func (a *ALB) Default() error {
+	if len(a.Hosts) == 0 && len(a.Paths) == 0 {
+		a.Paths = []string{"/*"}
+	}
+
+	return nil
+}
+
+// Validate implementation.
+func (a *ALB) Validate() error {
+	if err := validate.RequiredString(a.ListenerArn); err != nil {
+		return errors.Wrap(err, ".listener_arn")
+	}
+
+	if err := validate.Range(a.Priority, 1, 50000); err != nil {
+		return errors.Wrap(err, ".priority")
+	}
+
+	return nil
+}
@@ -0,0 +1,34 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/tj/assert"
+)
+
+func TestALB(t *testing.T) {
+	t.Run("default", func(t *testing.T) {
+		a := ALB{ListenerArn: "arn:aws:elasticloadbalancing:us-west-2:123:listener/app/internal/abc/def", Priority: 10}
+		assert.NoError(t, a.Default(), "default")
+		assert.NoError(t, a.Validate())
+		assert.Equal(t, []string{"/*"}, a.Paths)
+	})
+
+	t.Run("hosts", func(t *testing.T) {
+		a := ALB{Hosts: []string{"api.internal"}}
+		assert.NoError(t, a.Default(), "default")
+		assert.Empty(t, a.Paths)
+	})
+
+	t.Run("missing listener", func(t *testing.T) {
+		a := ALB{Priority: 10}
+		assert.NoError(t, a.Default(), "default")
+		assert.EqualError(t, a.Validate(), `.listener_arn: is required`)
+	})
+
+	t.Run("missing priority", func(t *testing.T) {
+		a := ALB{ListenerArn: "arn:aws:elasticloadbalancing:us-west-2:123:listener/app/internal/abc/def"}
+		assert.NoError(t, a.Default(), "default")
+		assert.EqualError(t, a.Validate(), `.priority: 0 is invalid, must be between 1 and 50000`)
+	})
+}
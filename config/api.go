@@ -0,0 +1,48 @@
+package config
+
+import (
+	"github.com/pkg/errors"
+
+	"github.com/apex/up/internal/validate"
+)
+
+// apiTypes is a list of supported API types.
+var apiTypes = []string{
+	"rest",
+	"http",
+	"url",
+}
+
+// bufferedRuntimes is a list of Lambda runtimes without response streaming.
+var bufferedRuntimes = []string{
+	"nodejs8.10",
+	"nodejs10.x",
+	"nodejs12.x",
+}
+
+// API config.
+type API struct {
+	// Type of the API Gateway API, a "rest" API using the
+	// payload format 1.0, or an "http" API using the 2.0 format.
+	// A "url" type uses Lambda Function URLs instead of API Gateway,
+	// streaming responses as they are written.
+	Type string `json:"type"`
+}
+
+// Default implementation.
+func (a *API) Default() error {
+	if a.Type == "" {
+		a.Type = "rest"
+	}
+
+	return nil
+}
+
+// Validate implementation.
+func (a *API) Validate() error {
+	if err := validate.List(a.Type, apiTypes); err != nil {
+		return errors.Wrap(err, ".type")
+	}
+
+	return nil
+}
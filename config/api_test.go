@@ -0,0 +1,26 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/tj/assert"
+)
+
+func TestAPI(t *testing.T) {
+	t.Run("default", func(t *testing.T) {
+		a := API{}
+		assert.NoError(t, a.Default(), "default")
+		assert.NoError(t, a.Validate())
+		assert.Equal(t, "rest", a.Type)
+	})
+
+	t.Run("invalid type", func(t *testing.T) {
+		a := API{Type: "websocket"}
+		assert.NoError(t, a.Default(), "default")
+		assert.EqualError(t, a.Validate(), `.type: "websocket" is invalid, must be one of:
+
+  • rest
+  • http
+  • url`)
+	})
+}
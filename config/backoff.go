@@ -3,6 +3,7 @@ package config
 import (
 	"time"
 	
+	"github.com/pkg/errors"
 	"github.com/tj/backoff"
 )
 
@@ -45,6 +46,27 @@ func (b *Backoff) Default() error {
 	return nil
 }
 
+// Validate implementation.
+func (b *Backoff) Validate() error {
+	if b.Min < 0 {
+		return errors.New(".min: should not be negative")
+	}
+
+	if b.Max < b.Min {
+		return errors.New(".max: should be >= .min")
+	}
+
+	if b.Factor < 1 {
+		return errors.New(".factor: should be >= 1")
+	}
+
+	if b.Attempts < 1 {
+		return errors.New(".attempts: should be greater than 0")
+	}
+
+	return nil
+}
+
 // Backoff returns the backoff from config.
 func (b *Backoff) Backoff() *backoff.Backoff {
 	return &backoff.Backoff{
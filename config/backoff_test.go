@@ -21,6 +21,15 @@ func TestBackoff_Default(t *testing.T) {
 	assert.Equal(t, b, a)
 }
 
+func TestBackoff_Validate(t *testing.T) {
+	a := &Backoff{}
+	assert.NoError(t, a.Default(), "default")
+	assert.NoError(t, a.Validate(), "validate")
+
+	a.Max = 50
+	assert.EqualError(t, a.Validate(), `.max: should be >= .min`)
+}
+
 func TestBackoff_Backoff(t *testing.T) {
 	a := &Backoff{}
 	assert.NoError(t, a.Default(), "default")
@@ -0,0 +1,63 @@
+package config
+
+import (
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// CircuitBreaker config.
+type CircuitBreaker struct {
+	// Restarts within the window which open the circuit.
+	Restarts int `json:"restarts"`
+
+	// Window in seconds in which restarts are counted.
+	Window int `json:"window"`
+
+	// Cooldown in seconds before the circuit half-opens.
+	Cooldown int `json:"cooldown"`
+}
+
+// Default implementation.
+func (c *CircuitBreaker) Default() error {
+	if c.Restarts == 0 {
+		c.Restarts = 5
+	}
+
+	if c.Window == 0 {
+		c.Window = 60
+	}
+
+	if c.Cooldown == 0 {
+		c.Cooldown = 30
+	}
+
+	return nil
+}
+
+// Validate implementation.
+func (c *CircuitBreaker) Validate() error {
+	if c.Restarts < 1 {
+		return errors.New(".restarts: should be greater than 0")
+	}
+
+	if c.Window < 1 {
+		return errors.New(".window: should be greater than 0")
+	}
+
+	if c.Cooldown < 1 {
+		return errors.New(".cooldown: should be greater than 0")
+	}
+
+	return nil
+}
+
+// WindowDuration returns the window as a duration.
+func (c *CircuitBreaker) WindowDuration() time.Duration {
+	return time.Duration(c.Window) * time.Second
+}
+
+// CooldownDuration returns the cooldown as a duration.
+func (c *CircuitBreaker) CooldownDuration() time.Duration {
+	return time.Duration(c.Cooldown) * time.Second
+}
@@ -0,0 +1,19 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/tj/assert"
+)
+
+func TestCircuitBreaker_Default(t *testing.T) {
+	c := &CircuitBreaker{}
+	assert.NoError(t, c.Default(), "default")
+	assert.Equal(t, &CircuitBreaker{Restarts: 5, Window: 60, Cooldown: 30}, c)
+	assert.NoError(t, c.Validate(), "validate")
+}
+
+func TestCircuitBreaker_Validate(t *testing.T) {
+	c := &CircuitBreaker{Restarts: -1, Window: 60, Cooldown: 30}
+	assert.EqualError(t, c.Validate(), `.restarts: should be greater than 0`)
+}
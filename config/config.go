@@ -3,7 +3,7 @@ package config
 import (
 	"encoding/json"
 	"io/ioutil"
-	"os"
+	"strings"
 
 	"github.com/apex/log"
 	"github.com/pkg/errors"
@@ -11,6 +11,7 @@ import (
 	"github.com/apex/up/internal/header"
 	"github.com/apex/up/internal/inject"
 	"github.com/apex/up/internal/redirect"
+	"github.com/apex/up/internal/util"
 	"github.com/apex/up/internal/validate"
 	"github.com/apex/up/platform/aws/regions"
 	"github.com/aws/aws-sdk-go/aws/session"
@@ -31,12 +32,12 @@ type Config struct {
 	Name        string         `json:"name"`
 	Description string         `json:"description"`
 	Type        string         `json:"type"`
+	Runtime     string         `json:"runtime"`
 	Headers     header.Rules   `json:"headers"`
 	Redirects   redirect.Rules `json:"redirects"`
 	Hooks       Hooks          `json:"hooks"`
 	Environment Environment    `json:"environment"`
 	Regions     []string       `json:"regions"`
-	Profile     string         `json:"profile"`
 	Inject      inject.Rules   `json:"inject"`
 	Lambda      Lambda         `json:"lambda"`
 	CORS        *CORS          `json:"cors"`
@@ -46,10 +47,28 @@ type Config struct {
 	Logs        Logs           `json:"logs"`
 	Stages      Stages         `json:"stages"`
 	DNS         DNS            `json:"dns"`
+	API         API            `json:"api"`
+	Crons       Crons          `json:"crons"`
+	Credentials
+
+	// vars resolves ${...} references, once.
+	vars *variables
+
+	// defaultBuild is the build hook defaulted by the runtime.
+	defaultBuild Hook
+
+	// configured regions, before targeting a single region.
+	configured []string
 }
 
 // Validate implementation.
 func (c *Config) Validate() error {
+	if c.vars != nil {
+		if err := c.vars.Err(); err != nil {
+			return err
+		}
+	}
+
 	if err := validate.RequiredString(c.Name); err != nil {
 		return errors.Wrap(err, ".name")
 	}
@@ -58,7 +77,7 @@ func (c *Config) Validate() error {
 		return errors.Wrapf(err, ".name %q", c.Name)
 	}
 
-	if err := validate.List(c.Type, []string{"static", "server"}); err != nil {
+	if err := validate.List(c.Type, []string{"static", "server", "go"}); err != nil {
 		return errors.Wrap(err, ".type")
 	}
 
@@ -66,6 +85,10 @@ func (c *Config) Validate() error {
 		return errors.Wrap(err, ".regions")
 	}
 
+	if err := c.Credentials.Validate(); err != nil {
+		return err
+	}
+
 	if err := c.DNS.Validate(); err != nil {
 		return errors.Wrap(err, ".dns")
 	}
@@ -82,6 +105,18 @@ func (c *Config) Validate() error {
 		return errors.Wrap(err, ".lambda")
 	}
 
+	if err := c.API.Validate(); err != nil {
+		return errors.Wrap(err, ".api")
+	}
+
+	if c.API.Type == "url" && len(c.Stages.Domains()) > 0 {
+		return errors.New(`.api: type "url" does not support stage domains`)
+	}
+
+	if c.API.Type == "url" && util.StringsContains(bufferedRuntimes, c.Lambda.Runtime) {
+		return errors.Errorf(`.api: type "url" streams responses, which the %s runtime does not support, use a .lambda.runtime of nodejs14.x or later`, c.Lambda.Runtime)
+	}
+
 	if err := c.Proxy.Validate(); err != nil {
 		return errors.Wrap(err, ".proxy")
 	}
@@ -90,8 +125,14 @@ func (c *Config) Validate() error {
 		return errors.Wrap(err, ".stages")
 	}
 
-	if len(c.Regions) > 1 {
-		return errors.New("multiple regions is not yet supported, see https://github.com/apex/up/issues/134")
+	if err := c.Crons.Validate(); err != nil {
+		return errors.Wrap(err, ".crons")
+	}
+
+	for i, v := range c.Crons {
+		if s := c.Stages.GetByName(v.Stage); s == nil || s.IsLocal() {
+			return errors.Errorf(".crons: cron %d: .stage: %q is not a remote stage", i, v.Stage)
+		}
 	}
 
 	return nil
@@ -110,10 +151,9 @@ func (c *Config) Default() error {
 		return errors.Wrap(err, ".stages")
 	}
 
-	// TODO: hack, move to the instantiation of aws clients
-	if c.Profile != "" {
-		setProfile(c.Profile)
-	}
+	// variables are resolved before anything else,
+	// and reported as validation errors by path.
+	c.interpolate()
 
 	// default type to server
 	if c.Type == "" {
@@ -122,11 +162,15 @@ func (c *Config) Default() error {
 
 	// runtime defaults
 	if c.Type != "static" {
-		runtime := inferRuntime()
-		log.WithField("type", runtime).Debug("inferred runtime")
+		runtime, err := c.runtime()
+		if err != nil {
+			return errors.Wrap(err, ".runtime")
+		}
 
-		if err := runtimeConfig(runtime, c); err != nil {
-			return errors.Wrap(err, "runtime")
+		if runtime != nil {
+			if err := runtimeConfig(runtime, c); err != nil {
+				return errors.Wrap(err, "runtime")
+			}
 		}
 	}
 
@@ -136,7 +180,7 @@ func (c *Config) Default() error {
 	}
 
 	// region globbing
-	c.Regions = regions.Match(c.Regions)
+	c.Regions = util.UniqueStrings(regions.Match(c.Regions))
 
 	// default .proxy
 	if err := c.Proxy.Default(); err != nil {
@@ -148,6 +192,16 @@ func (c *Config) Default() error {
 		return errors.Wrap(err, ".lambda")
 	}
 
+	// default .api
+	if err := c.API.Default(); err != nil {
+		return errors.Wrap(err, ".api")
+	}
+
+	// default .crons
+	if err := c.Crons.Default(); err != nil {
+		return errors.Wrap(err, ".crons")
+	}
+
 	// default .dns
 	if err := c.DNS.Default(); err != nil {
 		return errors.Wrap(err, ".dns")
@@ -176,18 +230,99 @@ func (c *Config) Default() error {
 	return nil
 }
 
-// Override with stage config if present, and re-validate.
+// Override with stage config if present, re-default and re-validate.
 func (c *Config) Override(stage string) error {
 	s := c.Stages.GetByName(stage)
 	if s == nil {
 		return nil
 	}
 
+	// resolve the stage values, and values deferred until the
+	// stage is known, before they replace the top-level values.
+	c.interpolate()
+	c.vars.interpolateStage(c, s)
+
 	s.Override(c)
 
+	if err := c.Default(); err != nil {
+		return errors.Wrap(err, "defaulting")
+	}
+
 	return c.Validate()
 }
 
+// TargetRegion restricts the config to a single region, retaining
+// the configured regions for multi-region resources such as domains.
+func (c *Config) TargetRegion(region string) {
+	if c.configured == nil {
+		c.configured = c.Regions
+	}
+
+	c.Regions = []string{region}
+}
+
+// ConfiguredRegions returns the configured regions, which may
+// differ from .regions when targeting a single region.
+func (c *Config) ConfiguredRegions() []string {
+	if c.configured != nil {
+		return c.configured
+	}
+
+	return c.Regions
+}
+
+// IsMultiRegion returns true if the app is configured for more than one region.
+func (c *Config) IsMultiRegion() bool {
+	return len(c.ConfiguredRegions()) > 1
+}
+
+// Variables returns the resolved values of variable references,
+// such as "env:NAME", excluding ${stage} and parameters, which
+// are secret and fetched by the deployed function instead.
+func (c *Config) Variables() map[string]string {
+	m := make(map[string]string)
+
+	if c.vars != nil {
+		for k, v := range c.vars.cache {
+			if !strings.HasPrefix(k, "ssm:") {
+				m[k] = v
+			}
+		}
+	}
+
+	return m
+}
+
+// interpolate resolves variables unless already resolved.
+func (c *Config) interpolate() {
+	if c.vars == nil {
+		c.vars = newVariables(c)
+	}
+
+	c.vars.interpolate(c)
+}
+
+// runtime returns the runtime specified by .runtime, or inferred
+// from the files present in the working directory, or nil.
+func (c *Config) runtime() (Runtime, error) {
+	if c.Runtime != "" {
+		r := GetRuntime(c.Runtime)
+		if r == nil {
+			return nil, validate.List(c.Runtime, RuntimeNames())
+		}
+
+		log.WithField("type", r.Name()).Debug("specified runtime")
+		return r, nil
+	}
+
+	r := inferRuntime()
+	if r != nil {
+		log.WithField("type", r.Name()).Debug("inferred runtime")
+	}
+
+	return r, nil
+}
+
 // defaultRegions checks AWS_REGION and falls back on us-west-2.
 func (c *Config) defaultRegions() error {
 	if len(c.Regions) != 0 {
@@ -197,6 +332,7 @@ func (c *Config) defaultRegions() error {
 
 	s, err := session.NewSessionWithOptions(session.Options{
 		SharedConfigState: session.SharedConfigEnable,
+		Profile:           c.Profile,
 	})
 
 	if err != nil {
@@ -217,12 +353,36 @@ func (c *Config) defaultRegions() error {
 
 // ParseConfig returns config from JSON bytes.
 func ParseConfig(b []byte) (*Config, error) {
+	return parseConfig(b, nil)
+}
+
+// ParseDeployedConfig returns config from JSON bytes, resolving variable
+// references from the values resolved when deploying, see Config.Variables.
+func ParseDeployedConfig(b []byte, vars map[string]string) (*Config, error) {
+	return parseConfig(b, func(v *variables) {
+		v.deployed = true
+
+		for k, s := range vars {
+			v.cache[k] = s
+		}
+	})
+}
+
+// parseConfig returns config from JSON bytes, with
+// variables configured by fn when non-nil.
+func parseConfig(b []byte, fn func(*variables)) (*Config, error) {
 	c := &Config{}
 
 	if err := json.Unmarshal(b, c); err != nil {
 		return nil, errors.Wrap(err, "parsing json")
 	}
 
+	c.vars = newVariables(c)
+
+	if fn != nil {
+		fn(c.vars)
+	}
+
 	if err := c.Default(); err != nil {
 		return nil, errors.Wrap(err, "defaulting")
 	}
@@ -249,9 +409,10 @@ func MustParseConfigString(s string) *Config {
 	return c
 }
 
-// ReadConfig reads the configuration from `path`.
+// ReadConfig reads the configuration from `path`, which
+// may be JSON, YAML or TOML depending on its extension.
 func ReadConfig(path string) (*Config, error) {
-	b, err := ioutil.ReadFile(path)
+	b, err := readConfig(path)
 	if err != nil {
 		return nil, err
 	}
@@ -259,7 +420,23 @@ func ReadConfig(path string) (*Config, error) {
 	return ParseConfig(b)
 }
 
-// setProfile sets the AWS_PROFILE.
-func setProfile(name string) {
-	os.Setenv("AWS_PROFILE", name)
+// ReadDeployedConfig reads the configuration from `path` like ReadConfig,
+// resolving variable references from the values resolved when deploying.
+func ReadDeployedConfig(path string, vars map[string]string) (*Config, error) {
+	b, err := readConfig(path)
+	if err != nil {
+		return nil, err
+	}
+
+	return ParseDeployedConfig(b, vars)
+}
+
+// readConfig returns the configuration at `path` as JSON.
+func readConfig(path string) ([]byte, error) {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	return toJSON(path, b)
 }
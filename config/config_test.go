@@ -68,7 +68,53 @@ func TestConfig_Type(t *testing.T) {
 		assert.EqualError(t, c.Validate(), `.type: "something" is invalid, must be one of:
 
   • static
-  • server`)
+  • server
+  • go`)
+	})
+}
+
+func TestConfig_API(t *testing.T) {
+	t.Run("url", func(t *testing.T) {
+		c := Config{
+			Name:   "api",
+			API:    API{Type: "url"},
+			Lambda: Lambda{Runtime: "nodejs20.x"},
+		}
+
+		assert.NoError(t, c.Default(), "default")
+		assert.NoError(t, c.Validate(), "validate")
+	})
+
+	t.Run("url without streaming", func(t *testing.T) {
+		c := Config{
+			Name: "api",
+			API:  API{Type: "url"},
+		}
+
+		assert.NoError(t, c.Default(), "default")
+		assert.EqualError(t, c.Validate(), `.api: type "url" streams responses, which the nodejs10.x runtime does not support, use a .lambda.runtime of nodejs14.x or later`)
+	})
+}
+
+func TestConfig_Crons(t *testing.T) {
+	t.Run("valid", func(t *testing.T) {
+		c := Config{
+			Name:  "api",
+			Crons: Crons{{Name: "cleanup", Schedule: "rate(1 hour)", Path: "/tasks/cleanup"}},
+		}
+
+		assert.NoError(t, c.Default(), "default")
+		assert.NoError(t, c.Validate(), "validate")
+	})
+
+	t.Run("local stage", func(t *testing.T) {
+		c := Config{
+			Name:  "api",
+			Crons: Crons{{Name: "cleanup", Schedule: "rate(1 hour)", Path: "/tasks/cleanup", Stage: "development"}},
+		}
+
+		assert.NoError(t, c.Default(), "default")
+		assert.EqualError(t, c.Validate(), `.crons: cron 0: .stage: "development" is not a remote stage`)
 	})
 }
 
@@ -106,7 +152,7 @@ func TestConfig_Regions(t *testing.T) {
 
 		assert.NoError(t, c.Default(), "default")
 		assert.NoError(t, c.Validate(), "validate")
-		assert.Equal(t, []string{"us-east-2", "us-east-1", "us-west-1", "us-west-2", "us-east-1", "ca-central-1"}, c.Regions)
+		assert.Equal(t, []string{"us-east-2", "us-east-1", "us-west-1", "us-west-2", "ca-central-1"}, c.Regions)
 	})
 
 	t.Run("invalid globbing", func(t *testing.T) {
@@ -166,6 +212,25 @@ func TestConfig_Regions(t *testing.T) {
 	})
 }
 
+func TestConfig_TargetRegion(t *testing.T) {
+	t.Run("multiple", func(t *testing.T) {
+		c := Config{Regions: []string{"us-west-2", "us-east-1"}}
+		c.TargetRegion("us-east-1")
+		assert.Equal(t, []string{"us-east-1"}, c.Regions)
+		assert.Equal(t, []string{"us-west-2", "us-east-1"}, c.ConfiguredRegions())
+		assert.True(t, c.IsMultiRegion())
+	})
+
+	t.Run("single", func(t *testing.T) {
+		c := Config{Regions: []string{"us-west-2"}}
+		assert.False(t, c.IsMultiRegion())
+		c.TargetRegion("us-east-1")
+		assert.Equal(t, []string{"us-east-1"}, c.Regions)
+		assert.Equal(t, []string{"us-west-2"}, c.ConfiguredRegions())
+		assert.False(t, c.IsMultiRegion())
+	})
+}
+
 func TestConfig_defaultRegions(t *testing.T) {
 	t.Run("regions from config", func(t *testing.T) {
 		regions := []string{"us-east-1"}
@@ -35,3 +35,54 @@ type CORS struct {
 	// Debugging flag adds additional output to debug server side CORS issues
 	Debug bool `json:"debug"`
 }
+
+// CORSOverrides is the stage override of CORS,
+// where unset fields retain their top-level value.
+type CORSOverrides struct {
+	AllowedOrigins   []string `json:"allowed_origins"`
+	AllowedMethods   []string `json:"allowed_methods"`
+	AllowedHeaders   []string `json:"allowed_headers"`
+	ExposedHeaders   []string `json:"exposed_headers"`
+	AllowCredentials *bool    `json:"allow_credentials"`
+	MaxAge           *int     `json:"max_age"`
+	Debug            *bool    `json:"debug"`
+}
+
+// Override config.
+func (o *CORSOverrides) Override(c *Config) {
+	if o == nil {
+		return
+	}
+
+	if c.CORS == nil {
+		c.CORS = &CORS{}
+	}
+
+	if v := o.AllowedOrigins; v != nil {
+		c.CORS.AllowedOrigins = v
+	}
+
+	if v := o.AllowedMethods; v != nil {
+		c.CORS.AllowedMethods = v
+	}
+
+	if v := o.AllowedHeaders; v != nil {
+		c.CORS.AllowedHeaders = v
+	}
+
+	if v := o.ExposedHeaders; v != nil {
+		c.CORS.ExposedHeaders = v
+	}
+
+	if v := o.AllowCredentials; v != nil {
+		c.CORS.AllowCredentials = *v
+	}
+
+	if v := o.MaxAge; v != nil {
+		c.CORS.MaxAge = *v
+	}
+
+	if v := o.Debug; v != nil {
+		c.CORS.Debug = *v
+	}
+}
@@ -0,0 +1,162 @@
+package config
+
+import (
+	"regexp"
+	"sync"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/credentials/stscreds"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/pkg/errors"
+)
+
+// roleARN matches IAM role ARNs.
+var roleARN = regexp.MustCompile(`^arn:[^:]+:iam::\d{12}:role/.+`)
+
+// assumed is a cache of assumed role credentials, shared between
+// sessions so that an MFA token is only prompted for once.
+var assumed = struct {
+	sync.Mutex
+	m map[Credentials]*credentials.Credentials
+}{
+	m: make(map[Credentials]*credentials.Credentials),
+}
+
+// Credentials config for the AWS account, which
+// may be overridden per-stage to deploy stages
+// to different accounts.
+type Credentials struct {
+	// Profile is the AWS shared config profile.
+	Profile string `json:"profile"`
+
+	// RoleARN is the IAM role assumed, using the profile's credentials.
+	RoleARN string `json:"role_arn"`
+
+	// ExternalID is the optional external ID of the role.
+	ExternalID string `json:"external_id"`
+
+	// MFASerial is the optional MFA device serial, prompting for a token.
+	MFASerial string `json:"mfa_serial"`
+}
+
+// Validate implementation.
+func (c *Credentials) Validate() error {
+	if c.RoleARN != "" && !roleARN.MatchString(c.RoleARN) {
+		return errors.Errorf(".role_arn: %q must be an IAM role ARN", c.RoleARN)
+	}
+
+	if c.RoleARN == "" && c.ExternalID != "" {
+		return errors.New(".external_id: requires .role_arn")
+	}
+
+	if c.RoleARN == "" && c.MFASerial != "" {
+		return errors.New(".mfa_serial: requires .role_arn")
+	}
+
+	return nil
+}
+
+// Override config. A profile replaces the credentials entirely,
+// while a role alone is assumed using the top-level profile.
+func (c *Credentials) Override(conf *Config) {
+	conf.Credentials = c.override(conf.Credentials)
+}
+
+// override returns credentials c overriding base.
+func (c *Credentials) override(base Credentials) Credentials {
+	if c.Profile != "" || c.RoleARN != "" {
+		base.RoleARN = c.RoleARN
+		base.ExternalID = c.ExternalID
+		base.MFASerial = c.MFASerial
+	}
+
+	if c.Profile != "" {
+		base.Profile = c.Profile
+	}
+
+	return base
+}
+
+// Session returns an AWS session for region, using the profile
+// and assuming the role when present. An empty region uses the
+// region of the shared config. Errors are reported by requests.
+func (c *Credentials) Session(region string) *session.Session {
+	config := aws.NewConfig()
+
+	if region != "" {
+		config = config.WithRegion(region)
+	}
+
+	s, err := session.NewSessionWithOptions(session.Options{
+		Config:                  *config,
+		Profile:                 c.Profile,
+		SharedConfigState:       session.SharedConfigEnable,
+		AssumeRoleTokenProvider: stscreds.StdinTokenProvider,
+	})
+
+	if err != nil {
+		s = session.New(config)
+		s.Handlers.Validate.PushBack(func(r *request.Request) {
+			r.Error = errors.Wrap(err, "creating session")
+		})
+		return s
+	}
+
+	if c.RoleARN == "" {
+		return s
+	}
+
+	return s.Copy(&aws.Config{
+		Credentials: c.assume(s),
+	})
+}
+
+// Env returns environment variables providing the credentials to commands
+// such as the aws cli, the profile, or the temporary keys of the role, as
+// a role of the config may not be assumable from the shared config alone.
+func (c *Credentials) Env() ([]string, error) {
+	if c.RoleARN == "" {
+		if c.Profile == "" {
+			return nil, nil
+		}
+
+		return []string{"AWS_PROFILE=" + c.Profile}, nil
+	}
+
+	v, err := c.Session("").Config.Credentials.Get()
+	if err != nil {
+		return nil, errors.Wrap(err, "assuming role")
+	}
+
+	return []string{
+		"AWS_ACCESS_KEY_ID=" + v.AccessKeyID,
+		"AWS_SECRET_ACCESS_KEY=" + v.SecretAccessKey,
+		"AWS_SESSION_TOKEN=" + v.SessionToken,
+	}, nil
+}
+
+// assume returns the cached credentials of the role, assumed using s.
+func (c *Credentials) assume(s *session.Session) *credentials.Credentials {
+	assumed.Lock()
+	defer assumed.Unlock()
+
+	if creds, ok := assumed.m[*c]; ok {
+		return creds
+	}
+
+	creds := stscreds.NewCredentials(s, c.RoleARN, func(p *stscreds.AssumeRoleProvider) {
+		if c.ExternalID != "" {
+			p.ExternalID = aws.String(c.ExternalID)
+		}
+
+		if c.MFASerial != "" {
+			p.SerialNumber = aws.String(c.MFASerial)
+			p.TokenProvider = stscreds.StdinTokenProvider
+		}
+	})
+
+	assumed.m[*c] = creds
+	return creds
+}
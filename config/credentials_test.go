@@ -0,0 +1,78 @@
+package config
+
+import (
+	"os"
+	"testing"
+
+	"github.com/tj/assert"
+)
+
+func TestCredentials_Validate(t *testing.T) {
+	t.Run("valid", func(t *testing.T) {
+		c := Credentials{RoleARN: "arn:aws:iam::123456789012:role/deploy", ExternalID: "up", MFASerial: "arn:aws:iam::123456789012:mfa/tj"}
+		assert.NoError(t, c.Validate())
+	})
+
+	t.Run("invalid role", func(t *testing.T) {
+		c := Credentials{RoleARN: "deploy"}
+		assert.EqualError(t, c.Validate(), `.role_arn: "deploy" must be an IAM role ARN`)
+	})
+
+	t.Run("external id without role", func(t *testing.T) {
+		c := Credentials{ExternalID: "up"}
+		assert.EqualError(t, c.Validate(), `.external_id: requires .role_arn`)
+	})
+}
+
+func TestCredentials_Env(t *testing.T) {
+	t.Run("none", func(t *testing.T) {
+		c := Credentials{}
+		env, err := c.Env()
+		assert.NoError(t, err, "env")
+		assert.Empty(t, env)
+	})
+
+	t.Run("profile", func(t *testing.T) {
+		c := Credentials{Profile: "company"}
+		env, err := c.Env()
+		assert.NoError(t, err, "env")
+		assert.Equal(t, []string{"AWS_PROFILE=company"}, env)
+	})
+}
+
+func TestCredentials_Override(t *testing.T) {
+	os.Unsetenv("AWS_PROFILE")
+
+	c, err := ParseConfigString(`{
+		"name": "app",
+		"regions": ["us-west-2"],
+		"profile": "company",
+		"stages": {
+			"staging": {
+				"role_arn": "arn:aws:iam::111111111111:role/deploy",
+				"external_id": "up"
+			},
+			"production": {
+				"profile": "company-production"
+			}
+		}
+	}`)
+
+	assert.NoError(t, err, "parse")
+
+	staging := *c
+	assert.NoError(t, staging.Override("staging"), "override")
+	assert.Equal(t, Credentials{
+		Profile:    "company",
+		RoleARN:    "arn:aws:iam::111111111111:role/deploy",
+		ExternalID: "up",
+	}, staging.Credentials)
+
+	production := *c
+	production.RoleARN = "arn:aws:iam::111111111111:role/deploy"
+	assert.NoError(t, production.Override("production"), "override")
+	assert.Equal(t, Credentials{Profile: "company-production"}, production.Credentials)
+
+	// profiles are passed to sessions rather than the environment
+	assert.Empty(t, os.Getenv("AWS_PROFILE"))
+}
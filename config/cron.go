@@ -0,0 +1,115 @@
+package config
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"github.com/apex/up/internal/validate"
+)
+
+// cronMethods is a list of supported cron HTTP methods.
+var cronMethods = []string{
+	http.MethodGet,
+	http.MethodPost,
+	http.MethodPut,
+	http.MethodPatch,
+	http.MethodDelete,
+}
+
+// Cron config for a scheduled request to an app route.
+type Cron struct {
+	// Name of the cron, provided in the X-Up-Cron header field.
+	Name string `json:"name"`
+
+	// Schedule expression, such as "rate(1 hour)" or "cron(0 12 * * ? *)".
+	Schedule string `json:"schedule"`
+
+	// Method of the request.
+	Method string `json:"method"`
+
+	// Path of the request, including the query string.
+	Path string `json:"path"`
+
+	// Body of the request.
+	Body string `json:"body"`
+
+	// Stage invoked by the cron.
+	Stage string `json:"stage"`
+}
+
+// Default implementation.
+func (c *Cron) Default() error {
+	if c.Method == "" {
+		c.Method = http.MethodGet
+	}
+
+	if c.Stage == "" {
+		c.Stage = "production"
+	}
+
+	return nil
+}
+
+// Validate implementation.
+func (c *Cron) Validate() error {
+	if err := validate.Name(c.Name); err != nil {
+		return errors.Wrap(err, ".name")
+	}
+
+	if err := validate.RequiredString(c.Schedule); err != nil {
+		return errors.Wrap(err, ".schedule")
+	}
+
+	if !strings.HasPrefix(c.Schedule, "rate(") && !strings.HasPrefix(c.Schedule, "cron(") {
+		return errors.Errorf(".schedule: %q is invalid, must be a rate() or cron() expression", c.Schedule)
+	}
+
+	if err := validate.List(c.Method, cronMethods); err != nil {
+		return errors.Wrap(err, ".method")
+	}
+
+	if !strings.HasPrefix(c.Path, "/") {
+		return errors.Errorf(".path: %q is invalid, must begin with /", c.Path)
+	}
+
+	if err := validate.Stage(c.Stage); err != nil {
+		return errors.Wrap(err, ".stage")
+	}
+
+	return nil
+}
+
+// Crons config.
+type Crons []Cron
+
+// Default implementation.
+func (c Crons) Default() error {
+	for i := range c {
+		if err := c[i].Default(); err != nil {
+			return errors.Wrapf(err, "cron %d", i)
+		}
+	}
+
+	return nil
+}
+
+// Validate implementation.
+func (c Crons) Validate() error {
+	names := make(map[string]bool)
+
+	for i, v := range c {
+		if err := v.Validate(); err != nil {
+			return errors.Wrapf(err, "cron %d", i)
+		}
+
+		if names[v.Name] {
+			return errors.Errorf("cron %d: .name: %q is used by another cron", i, v.Name)
+		}
+
+		names[v.Name] = true
+	}
+
+	return nil
+}
@@ -0,0 +1,43 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/tj/assert"
+)
+
+func TestCrons_Validate(t *testing.T) {
+	t.Run("valid", func(t *testing.T) {
+		c := Crons{
+			{Name: "cleanup", Schedule: "rate(1 hour)", Method: "POST", Path: "/tasks/cleanup"},
+			{Name: "digest", Schedule: "cron(0 12 * * ? *)", Path: "/tasks/digest?period=daily", Stage: "staging"},
+		}
+
+		assert.NoError(t, c.Default(), "default")
+		assert.NoError(t, c.Validate())
+		assert.Equal(t, "production", c[0].Stage)
+		assert.Equal(t, "GET", c[1].Method)
+	})
+
+	t.Run("invalid schedule", func(t *testing.T) {
+		c := Crons{{Name: "cleanup", Schedule: "every hour", Path: "/tasks/cleanup"}}
+		assert.NoError(t, c.Default(), "default")
+		assert.EqualError(t, c.Validate(), `cron 0: .schedule: "every hour" is invalid, must be a rate() or cron() expression`)
+	})
+
+	t.Run("invalid path", func(t *testing.T) {
+		c := Crons{{Name: "cleanup", Schedule: "rate(1 hour)", Path: "tasks/cleanup"}}
+		assert.NoError(t, c.Default(), "default")
+		assert.EqualError(t, c.Validate(), `cron 0: .path: "tasks/cleanup" is invalid, must begin with /`)
+	})
+
+	t.Run("duplicate name", func(t *testing.T) {
+		c := Crons{
+			{Name: "cleanup", Schedule: "rate(1 hour)", Path: "/tasks/cleanup"},
+			{Name: "cleanup", Schedule: "rate(1 day)", Path: "/tasks/cleanup"},
+		}
+
+		assert.NoError(t, c.Default(), "default")
+		assert.EqualError(t, c.Validate(), `cron 1: .name: "cleanup" is used by another cron`)
+	})
+}
@@ -2,6 +2,7 @@ package config
 
 import (
 	"encoding/json"
+	"reflect"
 
 	"github.com/apex/up/internal/validate"
 	"github.com/pkg/errors"
@@ -44,6 +45,24 @@ func (d *DNS) UnmarshalJSON(b []byte) error {
 	return nil
 }
 
+// JSONSchema implementation.
+func (d *DNS) JSONSchema() map[string]interface{} {
+	record := schemaOf(reflect.TypeOf(Record{}))
+	props := record["properties"].(map[string]interface{})
+	props["type"] = map[string]interface{}{
+		"type": "string",
+		"enum": recordTypes,
+	}
+
+	return map[string]interface{}{
+		"type": "object",
+		"additionalProperties": map[string]interface{}{
+			"type":  "array",
+			"items": record,
+		},
+	}
+}
+
 // Default implementation.
 func (d *DNS) Default() error {
 	for _, z := range d.Zones {
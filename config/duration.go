@@ -35,3 +35,19 @@ func (d *Duration) UnmarshalJSON(b []byte) error {
 func (d *Duration) MarshalJSON() ([]byte, error) {
 	return []byte(strconv.Itoa(int(d.Seconds()))), nil
 }
+
+// JSONSchema implementation.
+func (d *Duration) JSONSchema() map[string]interface{} {
+	return map[string]interface{}{
+		"oneOf": []interface{}{
+			map[string]interface{}{
+				"type":        "integer",
+				"description": "Duration in seconds.",
+			},
+			map[string]interface{}{
+				"type":        "string",
+				"description": "Duration string such as \"1.5m\".",
+			},
+		},
+	}
+}
@@ -2,3 +2,18 @@ package config
 
 // Environment variables.
 type Environment map[string]string
+
+// Override config.
+func (e Environment) Override(c *Config) {
+	if len(e) == 0 {
+		return
+	}
+
+	if c.Environment == nil {
+		c.Environment = make(Environment)
+	}
+
+	for k, v := range e {
+		c.Environment[k] = v
+	}
+}
@@ -20,3 +20,30 @@ func (e *ErrorPages) Default() error {
 
 	return nil
 }
+
+// ErrorPagesOverrides is the stage override of ErrorPages,
+// where unset fields retain their top-level value.
+type ErrorPagesOverrides struct {
+	Enable    *bool                  `json:"enable"`
+	Dir       string                 `json:"dir"`
+	Variables map[string]interface{} `json:"variables"`
+}
+
+// Override config.
+func (e *ErrorPagesOverrides) Override(c *Config) {
+	if e.Enable != nil {
+		c.ErrorPages.Enable = *e.Enable
+	}
+
+	if e.Dir != "" {
+		c.ErrorPages.Dir = e.Dir
+	}
+
+	if len(e.Variables) > 0 && c.ErrorPages.Variables == nil {
+		c.ErrorPages.Variables = make(map[string]interface{})
+	}
+
+	for k, v := range e.Variables {
+		c.ErrorPages.Variables[k] = v
+	}
+}
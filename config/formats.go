@@ -0,0 +1,79 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+
+	"github.com/BurntSushi/toml"
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v3"
+
+	"github.com/apex/up/internal/util"
+)
+
+// Filenames of supported config files, in order of precedence.
+var Filenames = []string{
+	"up.json",
+	"up.yml",
+	"up.yaml",
+	"up.toml",
+}
+
+// FindConfig returns the path of the first config file present in
+// the working directory, falling back on "up.json" when none exist.
+func FindConfig() string {
+	for _, name := range Filenames {
+		if util.Exists(name) {
+			return name
+		}
+	}
+
+	return Filenames[0]
+}
+
+// toJSON converts YAML or TOML config to JSON based on the path's
+// extension, so that the JSON unmarshalers of each type are used.
+func toJSON(path string, b []byte) ([]byte, error) {
+	var v interface{}
+
+	switch filepath.Ext(path) {
+	case ".yml", ".yaml":
+		if err := yaml.Unmarshal(b, &v); err != nil {
+			return nil, errors.Wrap(err, "parsing yaml")
+		}
+	case ".toml":
+		if err := toml.Unmarshal(b, &v); err != nil {
+			return nil, errors.Wrap(err, "parsing toml")
+		}
+	default:
+		return b, nil
+	}
+
+	return json.Marshal(normalize(v))
+}
+
+// normalize converts maps with non-string keys, which YAML
+// produces for keys such as `404:`, to JSON-compatible maps.
+func normalize(v interface{}) interface{} {
+	switch v := v.(type) {
+	case map[interface{}]interface{}:
+		m := make(map[string]interface{})
+		for k, v := range v {
+			m[fmt.Sprint(k)] = normalize(v)
+		}
+		return m
+	case map[string]interface{}:
+		for k, val := range v {
+			v[k] = normalize(val)
+		}
+		return v
+	case []interface{}:
+		for i, val := range v {
+			v[i] = normalize(val)
+		}
+		return v
+	default:
+		return v
+	}
+}
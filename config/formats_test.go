@@ -0,0 +1,72 @@
+package config
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/tj/assert"
+)
+
+func TestReadConfig_formats(t *testing.T) {
+	dir, err := ioutil.TempDir("", "up-config")
+	assert.NoError(t, err, "tempdir")
+	defer os.RemoveAll(dir)
+
+	files := map[string]string{
+		"up.yml": `
+# comments are supported
+name: app
+regions: [us-west-2]
+hooks:
+  build: make build
+  clean:
+    - rm server
+    - rm -fr build
+error_pages:
+  variables:
+    404: Not found
+dns:
+  example.com:
+    - name: example.com
+      type: A
+      value: [1.1.1.1]
+`,
+		"up.toml": `
+# comments are supported
+name = "app"
+regions = ["us-west-2"]
+
+[hooks]
+build = "make build"
+clean = ["rm server", "rm -fr build"]
+
+[error_pages.variables]
+404 = "Not found"
+
+[[dns."example.com"]]
+name = "example.com"
+type = "A"
+value = ["1.1.1.1"]
+`,
+	}
+
+	for name, s := range files {
+		t.Run(name, func(t *testing.T) {
+			path := filepath.Join(dir, name)
+			assert.NoError(t, ioutil.WriteFile(path, []byte(s), 0644), "write")
+
+			c, err := ReadConfig(path)
+			assert.NoError(t, err, "read")
+
+			assert.Equal(t, "app", c.Name)
+			assert.Equal(t, Hook{"make build"}, c.Hooks.Build)
+			assert.Equal(t, Hook{"rm server", "rm -fr build"}, c.Hooks.Clean)
+			assert.Equal(t, "Not found", c.ErrorPages.Variables["404"])
+			assert.Len(t, c.DNS.Zones, 1)
+			assert.Equal(t, "example.com", c.DNS.Zones[0].Name)
+			assert.Equal(t, 300, c.DNS.Zones[0].Records[0].TTL)
+		})
+	}
+}
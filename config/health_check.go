@@ -0,0 +1,97 @@
+package config
+
+import (
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/apex/up/internal/validate"
+)
+
+// HealthCheck config.
+type HealthCheck struct {
+	// Path requested, checks are disabled when empty.
+	Path string `json:"path"`
+
+	// Status expected of a healthy response.
+	Status int `json:"status"`
+
+	// Interval in seconds between liveness checks.
+	Interval int `json:"interval"`
+
+	// Timeout in seconds of each check.
+	Timeout int `json:"timeout"`
+
+	// Threshold of consecutive failed liveness checks before restarting.
+	Threshold int `json:"threshold"`
+}
+
+// Enabled returns true if checks are enabled.
+func (h *HealthCheck) Enabled() bool {
+	return h.Path != ""
+}
+
+// Default implementation.
+func (h *HealthCheck) Default() error {
+	if !h.Enabled() {
+		return nil
+	}
+
+	if h.Status == 0 {
+		h.Status = 200
+	}
+
+	if h.Interval == 0 {
+		h.Interval = 30
+	}
+
+	if h.Timeout == 0 {
+		h.Timeout = 2
+	}
+
+	if h.Threshold == 0 {
+		h.Threshold = 3
+	}
+
+	return nil
+}
+
+// Validate implementation.
+func (h *HealthCheck) Validate() error {
+	if !h.Enabled() {
+		return nil
+	}
+
+	if !strings.HasPrefix(h.Path, "/") {
+		return errors.Errorf(".path: %q must begin with a slash", h.Path)
+	}
+
+	if err := validate.Range(h.Status, 100, 599); err != nil {
+		return errors.Wrap(err, ".status")
+	}
+
+	if h.Interval < 1 {
+		return errors.New(".interval: should be greater than 0")
+	}
+
+	if h.Timeout < 1 {
+		return errors.New(".timeout: should be greater than 0")
+	}
+
+	if h.Threshold < 1 {
+		return errors.New(".threshold: should be greater than 0")
+	}
+
+	return nil
+}
+
+// IntervalDuration returns the interval as a duration.
+func (h *HealthCheck) IntervalDuration() time.Duration {
+	return time.Duration(h.Interval) * time.Second
+}
+
+// TimeoutDuration returns the timeout as a duration.
+func (h *HealthCheck) TimeoutDuration() time.Duration {
+	return time.Duration(h.Timeout) * time.Second
+}
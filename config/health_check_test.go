@@ -0,0 +1,37 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/tj/assert"
+)
+
+func TestHealthCheck_Default(t *testing.T) {
+	t.Run("disabled", func(t *testing.T) {
+		h := &HealthCheck{}
+		assert.NoError(t, h.Default(), "default")
+		assert.Equal(t, &HealthCheck{}, h)
+	})
+
+	t.Run("enabled", func(t *testing.T) {
+		h := &HealthCheck{Path: "/health"}
+		assert.NoError(t, h.Default(), "default")
+		assert.Equal(t, &HealthCheck{
+			Path:      "/health",
+			Status:    200,
+			Interval:  30,
+			Timeout:   2,
+			Threshold: 3,
+		}, h)
+	})
+}
+
+func TestHealthCheck_Validate(t *testing.T) {
+	h := &HealthCheck{Path: "health"}
+	assert.NoError(t, h.Default(), "default")
+	assert.EqualError(t, h.Validate(), `.path: "health" must begin with a slash`)
+
+	h.Path = "/health"
+	h.Status = 1000
+	assert.EqualError(t, h.Validate(), `.status: 1000 is invalid, must be between 100 and 599`)
+}
@@ -86,3 +86,20 @@ func (h *Hook) UnmarshalJSON(b []byte) error {
 func (h *Hook) IsEmpty() bool {
 	return h == nil || len(*h) == 0
 }
+
+// JSONSchema implementation.
+func (h *Hook) JSONSchema() map[string]interface{} {
+	return map[string]interface{}{
+		"oneOf": []interface{}{
+			map[string]interface{}{
+				"type": "string",
+			},
+			map[string]interface{}{
+				"type": "array",
+				"items": map[string]interface{}{
+					"type": "string",
+				},
+			},
+		},
+	}
+}
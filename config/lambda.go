@@ -1,8 +1,47 @@
 package config
 
+import (
+	"reflect"
+	"regexp"
+	"sort"
+
+	"github.com/pkg/errors"
+
+	"github.com/apex/up/internal/util"
+	"github.com/apex/up/internal/validate"
+)
+
 // defaultRuntime is the default runtime.
 var defaultRuntime = "nodejs10.x"
 
+// defaultArchitecture is the default architecture.
+var defaultArchitecture = "x86_64"
+
+// architectures is a list of supported Lambda architectures.
+var architectures = []string{
+	"x86_64",
+	"arm64",
+}
+
+// tracingModes is a list of supported X-Ray tracing modes.
+var tracingModes = []string{
+	"PassThrough",
+	"Active",
+}
+
+// tracingPolicy is the function role policy for active tracing.
+var tracingPolicy = IAMPolicyStatement{
+	"Effect":   "Allow",
+	"Resource": "*",
+	"Action": []string{
+		"xray:PutTraceSegments",
+		"xray:PutTelemetryRecords",
+	},
+}
+
+// deadLetterARN matches SQS queue and SNS topic ARNs.
+var deadLetterARN = regexp.MustCompile(`^arn:[^:]+:(sqs|sns):`)
+
 // defaultPolicy is the default function role policy.
 var defaultPolicy = IAMPolicyStatement{
 	"Effect":   "Allow",
@@ -11,6 +50,7 @@ var defaultPolicy = IAMPolicyStatement{
 		"logs:CreateLogGroup",
 		"logs:CreateLogStream",
 		"logs:PutLogEvents",
+		"ssm:GetParameter",
 		"ssm:GetParametersByPath",
 		"ec2:CreateNetworkInterface",
 		"ec2:DescribeNetworkInterfaces",
@@ -18,9 +58,102 @@ var defaultPolicy = IAMPolicyStatement{
 	},
 }
 
+// lambdaRuntimes is a list of supported Lambda runtimes.
+var lambdaRuntimes = []string{
+	"nodejs8.10",
+	"nodejs10.x",
+	"nodejs12.x",
+	"nodejs14.x",
+	"nodejs16.x",
+	"nodejs18.x",
+	"nodejs20.x",
+	"nodejs22.x",
+}
+
+// x86Runtimes is a list of Lambda runtimes unavailable on arm64.
+var x86Runtimes = []string{
+	"nodejs8.10",
+	"nodejs10.x",
+}
+
+// statementKeys is a list of valid policy statement keys.
+var statementKeys = []string{
+	"Sid",
+	"Effect",
+	"Action",
+	"NotAction",
+	"Resource",
+	"NotResource",
+	"Condition",
+}
+
 // IAMPolicyStatement configuration.
 type IAMPolicyStatement map[string]interface{}
 
+// Validate implementation.
+func (p IAMPolicyStatement) Validate() error {
+	var keys []string
+	for k := range p {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	for _, k := range keys {
+		if err := validate.List(k, statementKeys); err != nil {
+			return errors.Wrapf(err, ".%s", k)
+		}
+	}
+
+	effect, ok := p["Effect"].(string)
+	if !ok {
+		return errors.New(".Effect: is required")
+	}
+
+	if err := validate.List(effect, []string{"Allow", "Deny"}); err != nil {
+		return errors.Wrap(err, ".Effect")
+	}
+
+	if err := p.oneOf("Action", "NotAction"); err != nil {
+		return err
+	}
+
+	if err := p.oneOf("Resource", "NotResource"); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// oneOf validates that exactly one of the keys is present,
+// and that its value is a string or list of strings.
+func (p IAMPolicyStatement) oneOf(a, b string) error {
+	_, hasA := p[a]
+	_, hasB := p[b]
+
+	switch {
+	case hasA && hasB:
+		return errors.Errorf(".%s: cannot be used with %s", b, a)
+	case hasB:
+		a = b
+	case !hasA:
+		return errors.Errorf(".%s: is required", a)
+	}
+
+	switch v := p[a].(type) {
+	case string, []string:
+		return nil
+	case []interface{}:
+		for i, s := range v {
+			if _, ok := s.(string); !ok {
+				return errors.Errorf(".%s: at index %d: must be a string", a, i)
+			}
+		}
+		return nil
+	default:
+		return errors.Errorf(".%s: must be a string or list of strings", a)
+	}
+}
+
 // VPC configuration.
 type VPC struct {
 	Subnets        []string `json:"subnets"`
@@ -41,11 +174,32 @@ type Lambda struct {
 	// Runtime of the function.
 	Runtime string `json:"runtime"`
 
+	// Architecture of the function.
+	Architecture string `json:"architecture"`
+
 	// Policy of the function role.
 	Policy []IAMPolicyStatement `json:"policy"`
 
 	// VPC configuration.
 	VPC *VPC `json:"vpc"`
+
+	// Layers of the function, as version ARNs.
+	Layers []string `json:"layers"`
+
+	// ReservedConcurrency of the function, zero is unreserved.
+	ReservedConcurrency int `json:"reserved_concurrency"`
+
+	// ProvisionedConcurrency of the stage alias.
+	ProvisionedConcurrency int `json:"provisioned_concurrency"`
+
+	// EphemeralStorage is the size of /tmp in megabytes.
+	EphemeralStorage int `json:"ephemeral_storage"`
+
+	// Tracing mode of the function.
+	Tracing string `json:"tracing"`
+
+	// DeadLetterARN is the SQS queue or SNS topic of failed asynchronous invocations.
+	DeadLetterARN string `json:"dead_letter_arn"`
 }
 
 // Default implementation.
@@ -62,13 +216,90 @@ func (l *Lambda) Default() error {
 		l.Runtime = defaultRuntime
 	}
 
-	l.Policy = append(l.Policy, defaultPolicy)
+	if l.Architecture == "" {
+		l.Architecture = defaultArchitecture
+	}
+
+	if l.EphemeralStorage == 0 {
+		l.EphemeralStorage = 512
+	}
+
+	if l.Tracing == "" {
+		l.Tracing = "PassThrough"
+	}
+
+	// the config may be re-defaulted after stage overrides
+	if !l.hasPolicy(defaultPolicy) {
+		l.Policy = append(l.Policy, defaultPolicy)
+	}
+
+	if l.Tracing == "Active" && !l.hasPolicy(tracingPolicy) {
+		l.Policy = append(l.Policy, tracingPolicy)
+	}
+
+	if p := l.deadLetterPolicy(); p != nil && !l.hasPolicy(p) {
+		l.Policy = append(l.Policy, p)
+	}
 
 	return nil
 }
 
 // Validate implementation.
 func (l *Lambda) Validate() error {
+	if err := validate.Range(l.Memory, 128, 10240); err != nil {
+		return errors.Wrap(err, ".memory")
+	}
+
+	if err := validate.Range(l.Timeout, 1, 900); err != nil {
+		return errors.Wrap(err, ".timeout")
+	}
+
+	if err := validate.List(l.Runtime, lambdaRuntimes); err != nil {
+		return errors.Wrap(err, ".runtime")
+	}
+
+	if err := validate.List(l.Architecture, architectures); err != nil {
+		return errors.Wrap(err, ".architecture")
+	}
+
+	if l.Architecture == "arm64" && util.StringsContains(x86Runtimes, l.Runtime) {
+		return errors.Errorf(".architecture: arm64 is not supported by the %s runtime, use nodejs12.x or later", l.Runtime)
+	}
+
+	if err := validate.Range(l.EphemeralStorage, 512, 10240); err != nil {
+		return errors.Wrap(err, ".ephemeral_storage")
+	}
+
+	if err := validate.List(l.Tracing, tracingModes); err != nil {
+		return errors.Wrap(err, ".tracing")
+	}
+
+	if l.ReservedConcurrency < 0 {
+		return errors.New(".reserved_concurrency: must not be negative")
+	}
+
+	if l.ProvisionedConcurrency < 0 {
+		return errors.New(".provisioned_concurrency: must not be negative")
+	}
+
+	if n := l.ReservedConcurrency; n > 0 && l.ProvisionedConcurrency > n {
+		return errors.Errorf(".provisioned_concurrency: must not exceed the reserved concurrency of %d", n)
+	}
+
+	if err := validate.RequiredStrings(l.Layers); err != nil {
+		return errors.Wrap(err, ".layers")
+	}
+
+	if l.DeadLetterARN != "" && !deadLetterARN.MatchString(l.DeadLetterARN) {
+		return errors.Errorf(".dead_letter_arn: %q must be an SQS queue or SNS topic ARN", l.DeadLetterARN)
+	}
+
+	for i, p := range l.Policy {
+		if err := p.Validate(); err != nil {
+			return errors.Wrapf(err, ".policy[%d]", i)
+		}
+	}
+
 	return nil
 }
 
@@ -93,4 +324,63 @@ func (l *Lambda) Override(c *Config) {
 	if l.Runtime != "" {
 		c.Lambda.Runtime = l.Runtime
 	}
+
+	if l.Architecture != "" {
+		c.Lambda.Architecture = l.Architecture
+	}
+
+	if l.Policy != nil {
+		c.Lambda.Policy = l.Policy
+	}
+
+	if l.Layers != nil {
+		c.Lambda.Layers = l.Layers
+	}
+
+	if l.ProvisionedConcurrency != 0 {
+		c.Lambda.ProvisionedConcurrency = l.ProvisionedConcurrency
+	}
+
+	if l.EphemeralStorage != 0 {
+		c.Lambda.EphemeralStorage = l.EphemeralStorage
+	}
+
+	if l.Tracing != "" {
+		c.Lambda.Tracing = l.Tracing
+	}
+
+	if l.DeadLetterARN != "" {
+		c.Lambda.DeadLetterARN = l.DeadLetterARN
+	}
+}
+
+// deadLetterPolicy returns the policy statement allowing delivery
+// to the dead-letter queue or topic, or nil when not configured.
+func (l *Lambda) deadLetterPolicy() IAMPolicyStatement {
+	m := deadLetterARN.FindStringSubmatch(l.DeadLetterARN)
+	if m == nil {
+		return nil
+	}
+
+	action := "sqs:SendMessage"
+	if m[1] == "sns" {
+		action = "sns:Publish"
+	}
+
+	return IAMPolicyStatement{
+		"Effect":   "Allow",
+		"Resource": l.DeadLetterARN,
+		"Action":   []string{action},
+	}
+}
+
+// hasPolicy returns true if the policy statement is present.
+func (l *Lambda) hasPolicy(policy IAMPolicyStatement) bool {
+	for _, p := range l.Policy {
+		if reflect.DeepEqual(p, policy) {
+			return true
+		}
+	}
+
+	return false
 }
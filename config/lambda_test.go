@@ -40,3 +40,149 @@ func TestLambda_Policy(t *testing.T) {
 		assert.Equal(t, defaultPolicy, c.Policy[1])
 	})
 }
+
+func TestLambda_Validate(t *testing.T) {
+	t.Run("valid", func(t *testing.T) {
+		c := &Lambda{}
+		assert.NoError(t, c.Default(), "default")
+		assert.NoError(t, c.Validate(), "validate")
+	})
+
+	t.Run("memory", func(t *testing.T) {
+		c := &Lambda{Memory: 64}
+		assert.NoError(t, c.Default(), "default")
+		assert.EqualError(t, c.Validate(), `.memory: 64 is invalid, must be between 128 and 10240`)
+	})
+
+	t.Run("timeout", func(t *testing.T) {
+		c := &Lambda{Timeout: 1000}
+		assert.NoError(t, c.Default(), "default")
+		assert.EqualError(t, c.Validate(), `.timeout: 1000 is invalid, must be between 1 and 900`)
+	})
+
+	t.Run("runtime", func(t *testing.T) {
+		c := &Lambda{Runtime: "nodejs4.3"}
+		assert.NoError(t, c.Default(), "default")
+		assert.Contains(t, c.Validate().Error(), `.runtime: "nodejs4.3" is invalid, must be one of:`)
+	})
+}
+
+func TestIAMPolicyStatement_Validate(t *testing.T) {
+	t.Run("valid", func(t *testing.T) {
+		s := IAMPolicyStatement{
+			"Effect":   "Allow",
+			"Resource": "*",
+			"Action":   []interface{}{"s3:List*", "s3:Get*"},
+		}
+
+		assert.NoError(t, s.Validate())
+	})
+
+	t.Run("unknown key", func(t *testing.T) {
+		s := IAMPolicyStatement{
+			"Effect":   "Allow",
+			"Resource": "*",
+			"Actions":  "s3:*",
+		}
+
+		assert.Contains(t, s.Validate().Error(), `.Actions: "Actions" is invalid, must be one of:`)
+	})
+
+	t.Run("effect", func(t *testing.T) {
+		s := IAMPolicyStatement{
+			"Effect":   "allow",
+			"Resource": "*",
+			"Action":   "s3:*",
+		}
+
+		assert.Contains(t, s.Validate().Error(), `.Effect: "allow" is invalid, must be one of:`)
+	})
+
+	t.Run("missing resource", func(t *testing.T) {
+		s := IAMPolicyStatement{
+			"Effect": "Allow",
+			"Action": "s3:*",
+		}
+
+		assert.EqualError(t, s.Validate(), `.Resource: is required`)
+	})
+
+	t.Run("conflicting action", func(t *testing.T) {
+		s := IAMPolicyStatement{
+			"Effect":    "Allow",
+			"Resource":  "*",
+			"Action":    "s3:*",
+			"NotAction": "s3:Delete*",
+		}
+
+		assert.EqualError(t, s.Validate(), `.NotAction: cannot be used with Action`)
+	})
+
+	t.Run("invalid action", func(t *testing.T) {
+		s := IAMPolicyStatement{
+			"Effect":   "Allow",
+			"Resource": "*",
+			"Action":   []interface{}{"s3:*", 5},
+		}
+
+		assert.EqualError(t, s.Validate(), `.Action: at index 1: must be a string`)
+	})
+}
+
+func TestLambda_settings(t *testing.T) {
+	t.Run("defaults", func(t *testing.T) {
+		c := &Lambda{}
+		assert.NoError(t, c.Default(), "default")
+		assert.Equal(t, "x86_64", c.Architecture)
+		assert.Equal(t, 512, c.EphemeralStorage)
+		assert.Equal(t, "PassThrough", c.Tracing)
+		assert.Len(t, c.Policy, 1)
+	})
+
+	t.Run("tracing and dead-letter policies", func(t *testing.T) {
+		c := &Lambda{
+			Tracing:       "Active",
+			DeadLetterARN: "arn:aws:sqs:us-west-2:123456789012:failed",
+		}
+
+		assert.NoError(t, c.Default(), "default")
+		assert.NoError(t, c.Default(), "default")
+		assert.NoError(t, c.Validate(), "validate")
+		assert.Len(t, c.Policy, 3)
+		assert.Equal(t, tracingPolicy, c.Policy[1])
+		assert.Equal(t, []string{"sqs:SendMessage"}, c.Policy[2]["Action"])
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		cases := map[string]Lambda{
+			`.ephemeral_storage: 20000 is invalid, must be between 512 and 10240`:                      {EphemeralStorage: 20000},
+			`.provisioned_concurrency: must not exceed the reserved concurrency of 5`:                  {ReservedConcurrency: 5, ProvisionedConcurrency: 10},
+			`.reserved_concurrency: must not be negative`:                                              {ReservedConcurrency: -1},
+			`.dead_letter_arn: "arn:aws:s3:::bucket" must be an SQS queue or SNS topic ARN`:            {DeadLetterARN: "arn:aws:s3:::bucket"},
+			`.layers: at index 0: is required`:                                                         {Layers: []string{""}},
+			`.architecture: arm64 is not supported by the nodejs10.x runtime, use nodejs12.x or later`: {Architecture: "arm64"},
+		}
+
+		for msg, c := range cases {
+			c := c
+			assert.NoError(t, c.Default(), "default")
+			assert.EqualError(t, c.Validate(), msg)
+		}
+	})
+
+	t.Run("override", func(t *testing.T) {
+		c := &Config{}
+		l := &Lambda{
+			Architecture:           "arm64",
+			Layers:                 []string{"arn:aws:lambda:us-west-2:123456789012:layer:ruby:1"},
+			ProvisionedConcurrency: 2,
+			Tracing:                "Active",
+		}
+
+		l.Override(c)
+		assert.Equal(t, "arm64", c.Lambda.Architecture)
+		assert.Equal(t, l.Layers, c.Lambda.Layers)
+		assert.Equal(t, 2, c.Lambda.ProvisionedConcurrency)
+		assert.Equal(t, "Active", c.Lambda.Tracing)
+	})
+}
@@ -0,0 +1,338 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// Problem is a config problem located in the source file.
+type Problem struct {
+	// Path of the file.
+	Path string
+
+	// Line and Column of the problem, zero when unknown.
+	Line   int
+	Column int
+
+	// Key is the JSON path of the value such as ".lambda.memory".
+	Key string
+
+	// Message describing the problem.
+	Message string
+}
+
+// Error implementation.
+func (p Problem) Error() string {
+	s := p.Path
+
+	if p.Line > 0 {
+		s += fmt.Sprintf(":%d:%d", p.Line, p.Column)
+	}
+
+	if p.Key != "" {
+		return fmt.Sprintf("%s: %s: %s", s, p.Key, p.Message)
+	}
+
+	return fmt.Sprintf("%s: %s", s, p.Message)
+}
+
+// Problems is a list of config problems.
+type Problems []Problem
+
+// Error implementation.
+func (p Problems) Error() string {
+	var lines []string
+
+	for _, v := range p {
+		lines = append(lines, v.Error())
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// Lint the config file at path, returning problems such as unknown
+// keys and invalid values, located by line and column. An error
+// is returned when the file cannot be read or parsed.
+func Lint(path string) (Problems, error) {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	root, err := parseNodes(path, b)
+	if err != nil {
+		return nil, err
+	}
+
+	l := &linter{path: path, root: root}
+	l.unknownKeys(root, Schema(), "")
+
+	b, err = toJSON(path, b)
+	if err != nil {
+		return nil, err
+	}
+
+	c, err := parseConfig(b, offline)
+	if err != nil {
+		l.error("", err)
+		return l.sorted(), nil
+	}
+
+	// validate each stage with its overrides applied
+	// to a copy, as overriding modifies the config
+	for _, name := range c.Stages.Names() {
+		c, err := parseConfig(b, environless)
+		if err != nil {
+			return nil, err
+		}
+
+		if err := c.Override(name); err != nil {
+			l.error(".stages."+name, err)
+		}
+	}
+
+	return l.sorted(), nil
+}
+
+// offline disables network resolution of variables while linting.
+func offline(v *variables) {
+	v.offline = true
+}
+
+// environless disables resolution of environment variables as well,
+// as a stage's variables may only be defined when deploying it.
+func environless(v *variables) {
+	v.offline = true
+	v.environless = true
+}
+
+// linter accumulates problems.
+type linter struct {
+	path     string
+	root     *node
+	problems Problems
+}
+
+// add a problem for key at the given node.
+func (l *linter) add(n *node, key, msg string) {
+	p := Problem{
+		Path:    l.path,
+		Key:     key,
+		Message: msg,
+	}
+
+	if n != nil {
+		p.Line, p.Column = n.line, n.column
+	}
+
+	for _, v := range l.problems {
+		if v == p {
+			return
+		}
+	}
+
+	l.problems = append(l.problems, p)
+}
+
+// sorted returns the problems sorted by position.
+func (l *linter) sorted() Problems {
+	sort.SliceStable(l.problems, func(i, j int) bool {
+		a, b := l.problems[i], l.problems[j]
+		if a.Line != b.Line {
+			return a.Line < b.Line
+		}
+		return a.Column < b.Column
+	})
+
+	return l.problems
+}
+
+// unknownKeys reports object keys not present in the schema.
+func (l *linter) unknownKeys(n *node, schema map[string]interface{}, path string) {
+	switch {
+	case n.object:
+		props, _ := schema["properties"].(map[string]interface{})
+
+		for _, k := range n.keys {
+			v := n.fields[k]
+			key := path + "." + k
+
+			if s, ok := props[k].(map[string]interface{}); ok {
+				l.unknownKeys(v, s, key)
+				continue
+			}
+
+			switch s := schema["additionalProperties"].(type) {
+			case map[string]interface{}:
+				l.unknownKeys(v, s, key)
+			case bool:
+				if !s {
+					l.add(v, key, unknownKey(k, props))
+				}
+			}
+		}
+	case n.array:
+		if s, ok := schema["items"].(map[string]interface{}); ok {
+			for i, v := range n.items {
+				l.unknownKeys(v, s, fmt.Sprintf("%s[%d]", path, i))
+			}
+		}
+	}
+}
+
+// unknownKey returns an unknown key message, suggesting
+// the closest of the valid keys when one is similar.
+func unknownKey(key string, props map[string]interface{}) string {
+	best, min := "", 3
+
+	for k := range props {
+		d := distance(strings.ToLower(key), k)
+		if d < min || (d == min && best != "" && k < best) {
+			best, min = k, d
+		}
+	}
+
+	if best == "" {
+		return "unknown key"
+	}
+
+	return fmt.Sprintf("unknown key, did you mean %q?", best)
+}
+
+// distance returns the Levenshtein distance between a and b.
+func distance(a, b string) int {
+	prev := make([]int, len(b)+1)
+	curr := make([]int, len(b)+1)
+
+	for j := range prev {
+		prev[j] = j
+	}
+
+	for i := 1; i <= len(a); i++ {
+		curr[0] = i
+
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+
+			curr[j] = minInt(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+
+		prev, curr = curr, prev
+	}
+
+	return prev[len(b)]
+}
+
+// minInt returns the smallest of the given ints.
+func minInt(n int, rest ...int) int {
+	for _, v := range rest {
+		if v < n {
+			n = v
+		}
+	}
+
+	return n
+}
+
+// wrappers are error prefixes which do not contribute to the key.
+var wrappers = map[string]bool{
+	"defaulting":   true,
+	"validating":   true,
+	"parsing json": true,
+}
+
+// error adds a problem for a parsing, defaulting or validation error,
+// using its ".key: " prefixes to locate the value. Keys are located
+// under prefix first, falling back on the top-level key, and then on
+// the closest ancestor present in the file.
+func (l *linter) error(prefix string, err error) {
+	if e, ok := errors.Cause(err).(*json.UnmarshalTypeError); ok && e.Field != "" {
+		key := "." + e.Field
+		n, _ := l.find(key)
+		l.add(n, key, fmt.Sprintf("invalid %s value, must be %s", e.Value, e.Type))
+		return
+	}
+
+	key, msg := splitError(err.Error())
+
+	n, ok := l.find(prefix + key)
+	if !ok && prefix != "" {
+		if m, ok := l.find(key); ok {
+			n, prefix = m, ""
+		}
+	}
+
+	// errors such as runtime inference failures have no location
+	if prefix+key == "" {
+		n = nil
+	}
+
+	l.add(n, prefix+key, msg)
+}
+
+// splitError splits the key prefixes from an error message,
+// for example "validating: .lambda: .memory: must be ..."
+// returns ".lambda.memory" and "must be ...".
+func splitError(s string) (key, msg string) {
+	parts := strings.Split(s, ": ")
+
+	for i, p := range parts {
+		if key == "" && wrappers[p] {
+			continue
+		}
+
+		if !strings.HasPrefix(p, ".") && !strings.HasPrefix(p, "[") {
+			if key == "" {
+				return "", s
+			}
+			return key, strings.Join(parts[i:], ": ")
+		}
+
+		// values such as `.name "Foo"` or `.zone is invalid`
+		fields := strings.SplitN(p, " ", 2)
+		key += fields[0]
+
+		if len(fields) == 2 && !strings.HasPrefix(fields[1], `"`) {
+			return key, strings.Join(append([]string{fields[1]}, parts[i+1:]...), ": ")
+		}
+	}
+
+	return key, "is invalid"
+}
+
+// segment matches a path segment such as .name or [0].
+var segment = regexp.MustCompile(`\.([^.\[]+)|\[(\d+)\]`)
+
+// find returns the node at key, or its closest
+// ancestor and false when the key is missing.
+func (l *linter) find(key string) (*node, bool) {
+	n := l.root
+
+	for _, m := range segment.FindAllStringSubmatch(key, -1) {
+		var next *node
+
+		if m[1] != "" {
+			next = n.field(m[1])
+		} else if i, _ := strconv.Atoi(m[2]); i < len(n.items) {
+			next = n.items[i]
+		}
+
+		if next == nil {
+			return n, false
+		}
+
+		n = next
+	}
+
+	return n, true
+}
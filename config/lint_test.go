@@ -0,0 +1,190 @@
+package config
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/tj/assert"
+)
+
+// lint writes s to a file named name and lints it.
+func lint(t *testing.T, name, s string) Problems {
+	dir, err := ioutil.TempDir("", "up-lint")
+	assert.NoError(t, err, "tempdir")
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, name)
+	assert.NoError(t, ioutil.WriteFile(path, []byte(s), 0644), "write")
+
+	problems, err := Lint(path)
+	assert.NoError(t, err, "lint")
+
+	for i := range problems {
+		problems[i].Path = filepath.Base(problems[i].Path)
+	}
+
+	return problems
+}
+
+func TestLint(t *testing.T) {
+	t.Run("valid", func(t *testing.T) {
+		problems := lint(t, "up.json", `{
+  "name": "app",
+  "regions": ["us-west-2"],
+  "lambda": { "memory": 1024 }
+}`)
+
+		assert.Empty(t, problems)
+	})
+
+	t.Run("unknown keys", func(t *testing.T) {
+		problems := lint(t, "up.json", `{
+  "name": "app",
+  "lamda": {},
+  "error_pages": { "enabled": true },
+  "stages": {
+    "production": {
+      "domain": "example.com",
+      "lambda": { "memroy": 1024 }
+    }
+  },
+  "something": true
+}`)
+
+		assert.Equal(t, `up.json:3:3: .lamda: unknown key, did you mean "lambda"?
+up.json:4:20: .error_pages.enabled: unknown key, did you mean "enable"?
+up.json:8:19: .stages.production.lambda.memroy: unknown key, did you mean "memory"?
+up.json:11:3: .something: unknown key`, problems.Error())
+	})
+
+	t.Run("invalid values", func(t *testing.T) {
+		problems := lint(t, "up.json", `{
+  "name": "app",
+  "lambda": {
+    "memory": 50000
+  }
+}`)
+
+		assert.Equal(t, `up.json:4:5: .lambda.memory: 50000 is invalid, must be between 128 and 10240`, problems.Error())
+	})
+
+	t.Run("invalid types", func(t *testing.T) {
+		problems := lint(t, "up.json", `{
+  "name": "app",
+  "lambda": {
+    "timeout": "5"
+  }
+}`)
+
+		assert.Equal(t, `up.json:4:5: .lambda.timeout: invalid string value, must be int`, problems.Error())
+	})
+
+	t.Run("invalid stage values", func(t *testing.T) {
+		problems := lint(t, "up.json", `{
+  "name": "app",
+  "stages": {
+    "production": {
+      "lambda": {
+        "timeout": 1000
+      }
+    }
+  }
+}`)
+
+		assert.Equal(t, `up.json:6:9: .stages.production.lambda.timeout: 1000 is invalid, must be between 1 and 900`, problems.Error())
+	})
+
+	t.Run("invalid policy", func(t *testing.T) {
+		problems := lint(t, "up.json", `{
+  "name": "app",
+  "lambda": {
+    "policy": [
+      {
+        "Effect": "Allow",
+        "Resource": "*"
+      }
+    ]
+  }
+}`)
+
+		assert.Equal(t, `up.json:5:7: .lambda.policy[0].Action: is required`, problems.Error())
+	})
+
+	t.Run("ssm variables", func(t *testing.T) {
+		problems := lint(t, "up.json", `{
+  "name": "app",
+  "regions": ["us-west-2"],
+  "environment": {
+    "DATABASE_URL": "${ssm:/app/${stage}/database_url}"
+  },
+  "lambda": {
+    "role": "${env:UP_TEST_MISSING}"
+  }
+}`)
+
+		assert.Equal(t, `up.json:8:5: .lambda.role: environment variable "UP_TEST_MISSING" is not defined`, problems.Error())
+	})
+
+	t.Run("schema reference", func(t *testing.T) {
+		problems := lint(t, "up.json", `{
+  "$schema": "./up.schema.json",
+  "name": "app",
+  "regions": ["us-west-2"]
+}`)
+
+		assert.Empty(t, problems)
+	})
+
+	t.Run("stage environment variables", func(t *testing.T) {
+		problems := lint(t, "up.json", `{
+  "name": "app",
+  "regions": ["us-west-2"],
+  "stages": {
+    "production": {
+      "environment": {
+        "DATABASE_URL": "${env:UP_TEST_MISSING}"
+      }
+    }
+  }
+}`)
+
+		assert.Empty(t, problems)
+	})
+
+	t.Run("yaml", func(t *testing.T) {
+		problems := lint(t, "up.yml", `
+name: app
+lambda:
+  memory: 512
+  tiemout: 5
+`)
+
+		assert.Equal(t, `up.yml:5:3: .lambda.tiemout: unknown key, did you mean "timeout"?`, problems.Error())
+	})
+
+	t.Run("toml", func(t *testing.T) {
+		problems := lint(t, "up.toml", `
+name = "app"
+
+[lambda]
+memory = 512
+  tiemout = 5
+`)
+
+		assert.Equal(t, `up.toml:6:3: .lambda.tiemout: unknown key, did you mean "timeout"?`, problems.Error())
+	})
+}
+
+func TestLint_syntax(t *testing.T) {
+	dir, err := ioutil.TempDir("", "up-lint")
+	assert.NoError(t, err, "tempdir")
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "up.json")
+	assert.NoError(t, ioutil.WriteFile(path, []byte("{\n  \"name\": \"app\",\n}"), 0644), "write")
+
+	_, err = Lint(path)
+	assert.EqualError(t, err, `parsing json: line 3, column 1: invalid character '}' looking for beginning of object key string`)
+}
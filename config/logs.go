@@ -24,3 +24,26 @@ func (l *Logs) Default() error {
 
 	return nil
 }
+
+// LogsOverrides is the stage override of Logs,
+// where unset fields retain their top-level value.
+type LogsOverrides struct {
+	Disable *bool  `json:"disable"`
+	Stdout  string `json:"stdout"`
+	Stderr  string `json:"stderr"`
+}
+
+// Override config.
+func (l *LogsOverrides) Override(c *Config) {
+	if l.Disable != nil {
+		c.Logs.Disable = *l.Disable
+	}
+
+	if l.Stdout != "" {
+		c.Logs.Stdout = l.Stdout
+	}
+
+	if l.Stderr != "" {
+		c.Logs.Stderr = l.Stderr
+	}
+}
@@ -0,0 +1,328 @@
+package config
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v3"
+)
+
+// node is a config value with its position in the source file,
+// which is the position of its key for object fields.
+type node struct {
+	line   int
+	column int
+	object bool
+	array  bool
+	keys   []string
+	fields map[string]*node
+	items  []*node
+}
+
+// field returns the field node by key.
+func (n *node) field(key string) *node {
+	if n.fields == nil {
+		return nil
+	}
+
+	return n.fields[key]
+}
+
+// set field key to v.
+func (n *node) set(key string, v *node) {
+	if _, ok := n.fields[key]; !ok {
+		n.keys = append(n.keys, key)
+	}
+
+	n.fields[key] = v
+}
+
+// parseNodes parses the config source based on the path's extension.
+func parseNodes(path string, b []byte) (*node, error) {
+	switch filepath.Ext(path) {
+	case ".yml", ".yaml":
+		return parseYAMLNodes(b)
+	case ".toml":
+		return parseTOMLNodes(b)
+	default:
+		return parseJSONNodes(b)
+	}
+}
+
+// parseJSONNodes parses JSON into nodes.
+func parseJSONNodes(b []byte) (*node, error) {
+	var v interface{}
+
+	// syntax errors are reported from here as the
+	// decoder's token stream is less descriptive
+	if err := json.Unmarshal(b, &v); err != nil {
+		if e, ok := err.(*json.SyntaxError); ok {
+			line, col := position(b, int(e.Offset)-1)
+			return nil, errors.Errorf("parsing json: line %d, column %d: %s", line, col, e)
+		}
+
+		return nil, errors.Wrap(err, "parsing json")
+	}
+
+	p := &jsonParser{src: b, dec: json.NewDecoder(bytes.NewReader(b))}
+
+	n, err := p.parse()
+	if err != nil {
+		return nil, errors.Wrap(err, "parsing json")
+	}
+
+	return n, nil
+}
+
+// jsonParser builds nodes from JSON tokens.
+type jsonParser struct {
+	src []byte
+	dec *json.Decoder
+}
+
+// pos returns the position of the next token.
+func (p *jsonParser) pos() (int, int) {
+	i := int(p.dec.InputOffset())
+
+	for i < len(p.src) && strings.IndexByte(" \t\r\n,:", p.src[i]) != -1 {
+		i++
+	}
+
+	return position(p.src, i)
+}
+
+// parse the next value.
+func (p *jsonParser) parse() (*node, error) {
+	line, col := p.pos()
+	n := &node{line: line, column: col}
+
+	t, err := p.dec.Token()
+	if err != nil {
+		return nil, err
+	}
+
+	switch t {
+	case json.Delim('{'):
+		n.object = true
+		n.fields = make(map[string]*node)
+
+		for p.dec.More() {
+			line, col := p.pos()
+
+			k, err := p.dec.Token()
+			if err != nil {
+				return nil, err
+			}
+
+			v, err := p.parse()
+			if err != nil {
+				return nil, err
+			}
+
+			v.line, v.column = line, col
+			n.set(k.(string), v)
+		}
+
+		if _, err := p.dec.Token(); err != nil {
+			return nil, err
+		}
+	case json.Delim('['):
+		n.array = true
+
+		for p.dec.More() {
+			v, err := p.parse()
+			if err != nil {
+				return nil, err
+			}
+
+			n.items = append(n.items, v)
+		}
+
+		if _, err := p.dec.Token(); err != nil {
+			return nil, err
+		}
+	}
+
+	return n, nil
+}
+
+// position returns the line and column of offset i in b.
+func position(b []byte, i int) (line, col int) {
+	if i > len(b) {
+		i = len(b)
+	}
+
+	line = 1 + bytes.Count(b[:i], []byte("\n"))
+	col = 1 + i - (bytes.LastIndexByte(b[:i], '\n') + 1)
+	return
+}
+
+// parseYAMLNodes parses YAML into nodes.
+func parseYAMLNodes(b []byte) (*node, error) {
+	var doc yaml.Node
+
+	if err := yaml.Unmarshal(b, &doc); err != nil {
+		return nil, errors.Wrap(err, "parsing yaml")
+	}
+
+	if len(doc.Content) == 0 {
+		return &node{line: 1, column: 1}, nil
+	}
+
+	return yamlNode(doc.Content[0]), nil
+}
+
+// yamlNode returns the node for y.
+func yamlNode(y *yaml.Node) *node {
+	if y.Kind == yaml.AliasNode {
+		y = y.Alias
+	}
+
+	n := &node{line: y.Line, column: y.Column}
+
+	switch y.Kind {
+	case yaml.MappingNode:
+		n.object = true
+		n.fields = make(map[string]*node)
+
+		for i := 0; i+1 < len(y.Content); i += 2 {
+			k, v := y.Content[i], yamlNode(y.Content[i+1])
+
+			// merge keys such as <<: *defaults
+			if k.Value == "<<" && v.object {
+				for _, key := range v.keys {
+					n.set(key, v.fields[key])
+				}
+				continue
+			}
+
+			v.line, v.column = k.Line, k.Column
+			n.set(k.Value, v)
+		}
+	case yaml.SequenceNode:
+		n.array = true
+
+		for _, v := range y.Content {
+			n.items = append(n.items, yamlNode(v))
+		}
+	}
+
+	return n
+}
+
+// tomlHeader matches table headers such as [lambda] or [[items]].
+var tomlHeader = regexp.MustCompile(`^\[(\[?)\s*([^\[\]]+?)\s*\]\]?\s*(#.*)?$`)
+
+// parseTOMLNodes parses TOML into nodes. As the TOML parser does not
+// expose positions they're located with a line-based scan of the
+// source, falling back on the parent's position for inline tables.
+func parseTOMLNodes(b []byte) (*node, error) {
+	var v interface{}
+
+	if err := toml.Unmarshal(b, &v); err != nil {
+		return nil, errors.Wrap(err, "parsing toml")
+	}
+
+	positions := tomlPositions(b)
+	return tomlNode(v, "", 1, 1, positions), nil
+}
+
+// tomlNode returns the node for v at path.
+func tomlNode(v interface{}, path string, line, col int, positions map[string][2]int) *node {
+	if p, ok := positions[path]; ok {
+		line, col = p[0], p[1]
+	}
+
+	n := &node{line: line, column: col}
+
+	switch v := v.(type) {
+	case map[string]interface{}:
+		n.object = true
+		n.fields = make(map[string]*node)
+
+		for _, k := range sortedKeys(v) {
+			n.set(k, tomlNode(v[k], path+"."+k, line, col, positions))
+		}
+	case []map[string]interface{}:
+		n.array = true
+
+		for i, item := range v {
+			n.items = append(n.items, tomlNode(item, fmt.Sprintf("%s[%d]", path, i), line, col, positions))
+		}
+	case []interface{}:
+		n.array = true
+
+		for i, item := range v {
+			n.items = append(n.items, tomlNode(item, fmt.Sprintf("%s[%d]", path, i), line, col, positions))
+		}
+	}
+
+	return n
+}
+
+// tomlPositions returns the positions of table headers and keys by path.
+func tomlPositions(b []byte) map[string][2]int {
+	positions := make(map[string][2]int)
+	tables := make(map[string]int)
+	table := ""
+
+	s := bufio.NewScanner(bytes.NewReader(b))
+	for line := 1; s.Scan(); line++ {
+		text := s.Text()
+		trimmed := strings.TrimSpace(text)
+		col := 1 + len(text) - len(strings.TrimLeft(text, " \t"))
+
+		if m := tomlHeader.FindStringSubmatch(trimmed); m != nil {
+			table = tomlPath(m[2])
+
+			// arrays of tables are indexed by occurrence
+			if m[1] == "[" {
+				base := table
+				positions[base] = [2]int{line, col}
+				table = fmt.Sprintf("%s[%d]", base, tables[base])
+				tables[base]++
+			}
+
+			positions[table] = [2]int{line, col}
+			continue
+		}
+
+		if i := strings.Index(trimmed, "="); i > 0 && !strings.HasPrefix(trimmed, "#") {
+			path := table + tomlPath(trimmed[:i])
+			if _, ok := positions[path]; !ok {
+				positions[path] = [2]int{line, col}
+			}
+		}
+	}
+
+	return positions
+}
+
+// tomlPath returns a path for dotted TOML key s.
+func tomlPath(s string) (path string) {
+	for _, k := range strings.Split(s, ".") {
+		path += "." + strings.Trim(strings.TrimSpace(k), `"'`)
+	}
+
+	return
+}
+
+// sortedKeys returns the sorted keys of m.
+func sortedKeys(m map[string]interface{}) []string {
+	var keys []string
+
+	for k := range m {
+		keys = append(keys, k)
+	}
+
+	sort.Strings(keys)
+	return keys
+}
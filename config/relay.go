@@ -2,6 +2,8 @@ package config
 
 import (
 	"github.com/pkg/errors"
+
+	"github.com/apex/up/internal/validate"
 )
 
 // Relay config.
@@ -14,6 +16,53 @@ type Relay struct {
 
 	// ListenTimeout in seconds when waiting for the app to bind to PORT.
 	ListenTimeout int `json:"listen_timeout"`
+
+	// Protocol spoken by the app, "http" or "fastcgi".
+	Protocol string `json:"protocol"`
+
+	// DocumentRoot of the scripts of a FastCGI app.
+	DocumentRoot string `json:"document_root"`
+
+	// Socket enables relaying to a Unix domain socket at UP_SOCKET.
+	Socket bool `json:"socket"`
+
+	// Sidecars are companion processes started before the app.
+	Sidecars Sidecars `json:"sidecars"`
+
+	// Workers is the number of instances of the app to balance requests across.
+	Workers int `json:"workers"`
+
+	// Backoff of retried requests after a crash or restart.
+	Backoff Backoff `json:"backoff"`
+
+	// HealthCheck of the app, before forwarding traffic and while running.
+	HealthCheck HealthCheck `json:"health_check"`
+
+	// CircuitBreaker of the app when crash-looping.
+	CircuitBreaker CircuitBreaker `json:"circuit_breaker"`
+
+	// StopSignal sent to the app's process group when stopping.
+	StopSignal string `json:"stop_signal"`
+
+	// StopTimeout in seconds to drain requests and wait for the app to exit.
+	StopTimeout int `json:"stop_timeout"`
+}
+
+// protocols is a list of supported protocols.
+var protocols = []string{
+	"http",
+	"fastcgi",
+}
+
+// stopSignals is a list of supported stop signals.
+var stopSignals = []string{
+	"SIGTERM",
+	"SIGINT",
+	"SIGQUIT",
+	"SIGHUP",
+	"SIGUSR1",
+	"SIGUSR2",
+	"SIGKILL",
 }
 
 // Default implementation.
@@ -30,6 +79,52 @@ func (r *Relay) Default() error {
 		r.ListenTimeout = 15
 	}
 
+	if r.Protocol == "" {
+		r.Protocol = "http"
+	}
+
+	// FastCGI servers are spawned on a socket
+	if r.Protocol == "fastcgi" {
+		r.Socket = true
+
+		if r.DocumentRoot == "" {
+			r.DocumentRoot = "."
+		}
+	}
+
+	if r.Workers == 0 {
+		r.Workers = 1
+	}
+
+	if r.StopSignal == "" {
+		r.StopSignal = "SIGTERM"
+	}
+
+	if r.StopTimeout == 0 {
+		r.StopTimeout = 5
+	}
+
+	// retries are jittered unless configured otherwise
+	if r.Backoff == (Backoff{}) {
+		r.Backoff.Jitter = true
+	}
+
+	if err := r.Backoff.Default(); err != nil {
+		return errors.Wrap(err, ".backoff")
+	}
+
+	if err := r.Sidecars.Default(); err != nil {
+		return errors.Wrap(err, ".sidecars")
+	}
+
+	if err := r.HealthCheck.Default(); err != nil {
+		return errors.Wrap(err, ".health_check")
+	}
+
+	if err := r.CircuitBreaker.Default(); err != nil {
+		return errors.Wrap(err, ".circuit_breaker")
+	}
+
 	return nil
 }
 
@@ -55,12 +150,122 @@ func (r *Relay) Validate() error {
 		return errors.Wrap(err, ".timeout")
 	}
 
+	if err := validate.List(r.Protocol, protocols); err != nil {
+		return errors.Wrap(err, ".protocol")
+	}
+
+	if r.Workers < 1 {
+		err := errors.New("should be greater than 0")
+		return errors.Wrap(err, ".workers")
+	}
+
+	if r.Workers > 64 {
+		err := errors.New("should be <= 64")
+		return errors.Wrap(err, ".workers")
+	}
+
+	if err := validate.List(r.StopSignal, stopSignals); err != nil {
+		return errors.Wrap(err, ".stop_signal")
+	}
+
+	if r.StopTimeout < 0 {
+		err := errors.New("should not be negative")
+		return errors.Wrap(err, ".stop_timeout")
+	}
+
+	if r.StopTimeout > 25 {
+		err := errors.New("should be <= 25")
+		return errors.Wrap(err, ".stop_timeout")
+	}
+
+	if err := r.Backoff.Validate(); err != nil {
+		return errors.Wrap(err, ".backoff")
+	}
+
+	if err := r.Sidecars.Validate(); err != nil {
+		return errors.Wrap(err, ".sidecars")
+	}
+
+	if err := r.HealthCheck.Validate(); err != nil {
+		return errors.Wrap(err, ".health_check")
+	}
+
+	if err := r.CircuitBreaker.Validate(); err != nil {
+		return errors.Wrap(err, ".circuit_breaker")
+	}
+
 	return nil
 }
 
+// RelayOverrides is the stage override of Relay,
+// where unset fields retain their top-level value.
+type RelayOverrides struct {
+	Command        string         `json:"command"`
+	Timeout        int            `json:"timeout"`
+	ListenTimeout  int            `json:"listen_timeout"`
+	Protocol       string         `json:"protocol"`
+	DocumentRoot   string         `json:"document_root"`
+	Socket         *bool          `json:"socket"`
+	Sidecars       Sidecars       `json:"sidecars"`
+	Workers        int            `json:"workers"`
+	Backoff        Backoff        `json:"backoff"`
+	HealthCheck    HealthCheck    `json:"health_check"`
+	CircuitBreaker CircuitBreaker `json:"circuit_breaker"`
+	StopSignal     string         `json:"stop_signal"`
+	StopTimeout    int            `json:"stop_timeout"`
+}
+
 // Override config.
-func (r *Relay) Override(c *Config) {
+func (r *RelayOverrides) Override(c *Config) {
 	if r.Command != "" {
 		c.Proxy.Command = r.Command
 	}
+
+	if r.Timeout != 0 {
+		c.Proxy.Timeout = r.Timeout
+	}
+
+	if r.ListenTimeout != 0 {
+		c.Proxy.ListenTimeout = r.ListenTimeout
+	}
+
+	if r.Protocol != "" {
+		c.Proxy.Protocol = r.Protocol
+	}
+
+	if r.DocumentRoot != "" {
+		c.Proxy.DocumentRoot = r.DocumentRoot
+	}
+
+	if v := r.Socket; v != nil {
+		c.Proxy.Socket = *v
+	}
+
+	if r.Sidecars != nil {
+		c.Proxy.Sidecars = r.Sidecars
+	}
+
+	if r.Workers != 0 {
+		c.Proxy.Workers = r.Workers
+	}
+
+	if r.StopSignal != "" {
+		c.Proxy.StopSignal = r.StopSignal
+	}
+
+	if r.StopTimeout != 0 {
+		c.Proxy.StopTimeout = r.StopTimeout
+	}
+
+	if r.Backoff != (Backoff{}) {
+		c.Proxy.Backoff = r.Backoff
+	}
+
+	if r.HealthCheck.Enabled() {
+		c.Proxy.HealthCheck = r.HealthCheck
+	}
+
+	if r.CircuitBreaker != (CircuitBreaker{}) {
+		c.Proxy.CircuitBreaker = r.CircuitBreaker
+	}
 }
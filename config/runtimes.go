@@ -2,223 +2,1015 @@ package config
 
 import (
 	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"sync"
 
+	"github.com/BurntSushi/toml"
 	"github.com/apex/up/internal/util"
 	"github.com/pkg/errors"
 )
 
-// Runtime is an app runtime.
-type Runtime string
+// Runtime is an app runtime, providing config defaults
+// for the projects it detects in the working directory.
+//
+// Runtime was previously the string type of runtime names,
+// which is now RuntimeName. This is a breaking change for
+// programs using the config package directly.
+type Runtime interface {
+	// Name of the runtime, which may be used as the "runtime" config value.
+	Name() string
+
+	// Priority of detection, runtimes with a higher priority are detected first.
+	Priority() int
+
+	// Detect returns true if the working directory uses the runtime.
+	Detect() bool
+
+	// Command returns the default proxy command, or an empty string.
+	Command() string
+
+	// DevelopmentCommand returns the default proxy command
+	// of the development stage, or an empty string.
+	DevelopmentCommand() string
+
+	// BuildHook returns the default build hook, or nil.
+	BuildHook() Hook
+
+	// CleanHook returns the default clean hook, or nil.
+	CleanHook() Hook
+}
+
+// RuntimeConfigurer is implemented by runtimes which customize
+// the config further, it is called before defaults are applied.
+type RuntimeConfigurer interface {
+	Configure(*Config) error
+}
+
+// RuntimeName is the name of a runtime, such as "go".
+type RuntimeName string
+
+// RuntimeUnknown is the name of an unknown runtime.
+//
+// Deprecated: runtime inference now returns a nil Runtime.
+const RuntimeUnknown RuntimeName = "unknown"
 
 // Runtimes available.
 const (
-	RuntimeUnknown    Runtime = "unknown"
-	RuntimeGo                 = "go"
-	RuntimeNode               = "node"
-	RuntimeClojure            = "clojure"
-	RuntimeCrystal            = "crystal"
-	RuntimePython             = "python"
-	RuntimeStatic             = "static"
-	RuntimeJavaMaven          = "java maven"
-	RuntimeJavaGradle         = "java gradle"
+	RuntimeGo         = "go"
+	RuntimeNode       = "node"
+	RuntimeClojure    = "clojure"
+	RuntimeCrystal    = "crystal"
+	RuntimePython     = "python"
+	RuntimeStatic     = "static"
+	RuntimeJavaMaven  = "java maven"
+	RuntimeJavaGradle = "java gradle"
+	RuntimeRuby       = "ruby"
+	RuntimeRust       = "rust"
+	RuntimePHP        = "php"
+	RuntimeDeno       = "deno"
+	RuntimeDotnet     = "dotnet"
 )
 
-// inferRuntime returns the runtime based on files present in the CWD.
+// runtimes registered.
+var runtimes struct {
+	sync.Mutex
+	list []Runtime
+}
+
+// RegisterRuntime registers a runtime, replacing one of the same name.
+func RegisterRuntime(r Runtime) {
+	runtimes.Lock()
+	defer runtimes.Unlock()
+
+	for i, v := range runtimes.list {
+		if v.Name() == r.Name() {
+			runtimes.list[i] = r
+			return
+		}
+	}
+
+	runtimes.list = append(runtimes.list, r)
+}
+
+// Runtimes returns the registered runtimes in order of priority.
+func Runtimes() []Runtime {
+	runtimes.Lock()
+	defer runtimes.Unlock()
+
+	list := make([]Runtime, len(runtimes.list))
+	copy(list, runtimes.list)
+
+	sort.SliceStable(list, func(i, j int) bool {
+		return list[i].Priority() > list[j].Priority()
+	})
+
+	return list
+}
+
+// RuntimeNames returns the names of registered runtimes in order of priority.
+func RuntimeNames() (v []string) {
+	for _, r := range Runtimes() {
+		v = append(v, r.Name())
+	}
+
+	return
+}
+
+// GetRuntime returns the runtime by name, or nil.
+func GetRuntime(name string) Runtime {
+	for _, r := range Runtimes() {
+		if r.Name() == name {
+			return r
+		}
+	}
+
+	return nil
+}
+
+// inferRuntime returns the runtime based on files present in the CWD, or nil.
 func inferRuntime() Runtime {
-	switch {
-	case util.Exists("main.go"):
-		return RuntimeGo
-	case util.Exists("main.cr"):
-		return RuntimeCrystal
-	case util.Exists("package.json"):
-		return RuntimeNode
-	case util.Exists("app.js"):
-		return RuntimeNode
-	case util.Exists("project.clj"):
-		return RuntimeClojure
-	case util.Exists("pom.xml"):
-		return RuntimeJavaMaven
-	case util.Exists("build.gradle"):
-		return RuntimeJavaGradle
-	case util.Exists("app.py"):
-		return RuntimePython
-	case util.Exists("index.html"):
-		return RuntimeStatic
-	default:
-		return RuntimeUnknown
-	}
-}
-
-// runtimeConfig performs config inferences based on what Up thinks the runtime is.
-func runtimeConfig(runtime Runtime, c *Config) error {
-	switch runtime {
-	case RuntimeGo:
-		golang(c)
-	case RuntimeClojure:
-		clojureLein(c)
-	case RuntimeJavaMaven:
-		javaMaven(c)
-	case RuntimeJavaGradle:
-		javaGradle(c)
-	case RuntimeCrystal:
-		crystal(c)
-	case RuntimePython:
-		python(c)
-	case RuntimeStatic:
-		c.Type = "static"
-	case RuntimeNode:
-		if err := nodejs(c); err != nil {
-			return err
+	for _, r := range Runtimes() {
+		if r.Detect() {
+			return r
 		}
 	}
+
 	return nil
 }
 
-// golang config.
-func golang(c *Config) {
+// runtimeConfig applies the defaults of the runtime to the config.
+func runtimeConfig(r Runtime, c *Config) error {
+	// the build hook defaulted previously is regenerated, as it may
+	// depend on values overridden by a stage such as .lambda.architecture
+	if c.defaultBuild != nil && reflect.DeepEqual(c.Hooks.Build, c.defaultBuild) {
+		c.Hooks.Build = nil
+	}
+
+	defaulted := c.Hooks.Build.IsEmpty()
+
+	if v, ok := r.(RuntimeConfigurer); ok {
+		if err := v.Configure(c); err != nil {
+			return err
+		}
+	}
+
+	if c.Proxy.Command == "" {
+		c.Proxy.Command = r.Command()
+	}
+
 	if c.Hooks.Build.IsEmpty() {
-		c.Hooks.Build = Hook{`GOOS=linux GOARCH=amd64 go build -o server *.go`}
+		c.Hooks.Build = r.BuildHook()
+	}
+
+	if defaulted {
+		c.defaultBuild = c.Hooks.Build
 	}
 
 	if c.Hooks.Clean.IsEmpty() {
-		c.Hooks.Clean = Hook{`rm server`}
+		c.Hooks.Clean = r.CleanHook()
 	}
 
 	if s := c.Stages.GetByName("development"); s != nil {
 		if s.Proxy.Command == "" {
-			s.Proxy.Command = "go run *.go"
+			s.Proxy.Command = r.DevelopmentCommand()
 		}
 	}
+
+	return nil
 }
 
-// java gradle config.
-func javaGradle(c *Config) {
-	if c.Proxy.Command == "" {
-		c.Proxy.Command = "java -jar server.jar"
+func init() {
+	RegisterRuntime(golang{})
+	RegisterRuntime(crystal)
+	RegisterRuntime(nodejs{})
+	RegisterRuntime(clojureLein)
+	RegisterRuntime(javaMaven)
+	RegisterRuntime(javaGradle)
+	RegisterRuntime(python{})
+	RegisterRuntime(static{})
+	RegisterRuntime(rust{})
+	RegisterRuntime(deno{})
+	RegisterRuntime(ruby{})
+	RegisterRuntime(php{})
+	RegisterRuntime(dotnet{})
+}
+
+// runtime is a built-in runtime detected by the presence of files.
+type runtime struct {
+	name     string
+	priority int
+	files    []string
+	command  string
+	dev      string
+	build    func() Hook
+	clean    func() Hook
+}
+
+// Name implementation.
+func (r *runtime) Name() string {
+	return r.name
+}
+
+// Priority implementation.
+func (r *runtime) Priority() int {
+	return r.priority
+}
+
+// Detect implementation.
+func (r *runtime) Detect() bool {
+	for _, f := range r.files {
+		if util.Exists(f) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Command implementation.
+func (r *runtime) Command() string {
+	return r.command
+}
+
+// DevelopmentCommand implementation.
+func (r *runtime) DevelopmentCommand() string {
+	return r.dev
+}
+
+// BuildHook implementation.
+func (r *runtime) BuildHook() Hook {
+	if r.build == nil {
+		return nil
+	}
+
+	return r.build()
+}
+
+// CleanHook implementation.
+func (r *runtime) CleanHook() Hook {
+	if r.clean == nil {
+		return nil
+	}
+
+	return r.clean()
+}
+
+// hook returns a func returning the given commands.
+func hook(commands ...string) func() Hook {
+	return func() Hook {
+		return Hook(commands)
+	}
+}
+
+// wrapper returns a func returning the wrapper command
+// when the wrapper script is present, or command.
+func wrapper(script, wrapped, command string) func() Hook {
+	return func() Hook {
+		if util.Exists(script) {
+			return Hook{wrapped}
+		}
+
+		return Hook{command}
+	}
+}
+
+// golang runtime, building the root package or the cmd/<name> package of a module.
+type golang struct{}
+
+// Name implementation.
+func (golang) Name() string {
+	return RuntimeGo
+}
+
+// Priority implementation.
+func (golang) Priority() int {
+	return 80
+}
+
+// Detect implementation.
+func (g golang) Detect() bool {
+	return util.Exists("main.go") || (util.Exists("go.mod") && g.pkg("") != ".")
+}
+
+// Command implementation.
+func (golang) Command() string {
+	return ""
+}
+
+// DevelopmentCommand implementation.
+func (g golang) DevelopmentCommand() string {
+	return g.dev(g.pkg(""))
+}
+
+// BuildHook implementation.
+func (g golang) BuildHook() Hook {
+	return g.build(g.pkg(""), "amd64", "server")
+}
+
+// CleanHook implementation.
+func (golang) CleanHook() Hook {
+	return Hook{`rm server`}
+}
+
+// Configure targets the Lambda architecture, and prefers
+// the cmd/<name> package matching the app name. Apps of
+// type "go" are built as the Lambda binary itself.
+func (g golang) Configure(c *Config) error {
+	pkg := g.pkg(c.Name)
+
+	if c.Type == "go" {
+		if c.Proxy.Command == "" {
+			c.Proxy.Command = "./main"
+		}
+
+		if c.Hooks.Clean.IsEmpty() {
+			c.Hooks.Clean = Hook{`rm main`}
+		}
 	}
 
 	if c.Hooks.Build.IsEmpty() {
-		// assumes build results in a shaded jar named server.jar
-		if util.Exists("gradlew") {
-			c.Hooks.Build = Hook{`./gradlew clean build && cp build/libs/server.jar .`}
-		} else {
-			c.Hooks.Build = Hook{`gradle clean build && cp build/libs/server.jar .`}
+		c.Hooks.Build = g.build(pkg, goarch(c.Lambda.Architecture), g.binary(c))
+	}
+
+	if s := c.Stages.GetByName("development"); s != nil {
+		if s.Proxy.Command == "" {
+			s.Proxy.Command = g.dev(pkg)
 		}
 	}
 
-	if c.Hooks.Clean.IsEmpty() {
-		c.Hooks.Clean = Hook{`rm server.jar && gradle clean`}
+	return nil
+}
+
+// build returns the build hook for pkg, writing the binary to out.
+func (golang) build(pkg, arch, out string) Hook {
+	return Hook{`GOOS=linux GOARCH=` + arch + ` go build -o ` + out + ` ` + pkg}
+}
+
+// binary returns the name of the binary built, which for apps
+// of type "go" is the Lambda binary, served in-process.
+func (golang) binary(c *Config) string {
+	if c.Type == "go" {
+		return "main"
 	}
+
+	return "server"
 }
 
-// java maven config.
-func javaMaven(c *Config) {
-	if c.Proxy.Command == "" {
-		c.Proxy.Command = "java -jar server.jar"
+// dev returns the development command for pkg.
+func (golang) dev(pkg string) string {
+	return `go run ` + pkg
+}
+
+// pkg returns the main package to build. Without a go.mod the root *.go
+// files are built, otherwise the root package when main.go is present,
+// or the cmd/<name> package, preferring cmd/server, then the first.
+func (golang) pkg(name string) string {
+	if !util.Exists("go.mod") {
+		return "*.go"
 	}
 
-	if c.Hooks.Build.IsEmpty() {
-		// assumes package results in a shaded jar named server.jar
-		if util.Exists("mvnw") {
-			c.Hooks.Build = Hook{`./mvnw clean package && cp target/server.jar .`}
-		} else {
-			c.Hooks.Build = Hook{`mvn clean package && cp target/server.jar .`}
+	if util.Exists("main.go") {
+		return "."
+	}
+
+	matches, _ := filepath.Glob(filepath.Join("cmd", "*", "main.go"))
+	if len(matches) == 0 {
+		return "."
+	}
+
+	for _, n := range []string{name, "server"} {
+		for _, m := range matches {
+			if n != "" && filepath.Base(filepath.Dir(m)) == n {
+				return "./" + filepath.ToSlash(filepath.Dir(m))
+			}
 		}
 	}
 
-	if c.Hooks.Clean.IsEmpty() {
-		c.Hooks.Clean = Hook{`rm server.jar && mvn clean`}
+	return "./" + filepath.ToSlash(filepath.Dir(matches[0]))
+}
+
+// targetArch returns the target triple architecture of the Lambda architecture.
+func targetArch(arch string) string {
+	if arch == "arm64" {
+		return "aarch64"
 	}
+
+	return "x86_64"
 }
 
-// clojure lein config.
-func clojureLein(c *Config) {
-	if c.Proxy.Command == "" {
-		c.Proxy.Command = "java -jar server.jar"
+// goarch returns the GOARCH of the Lambda architecture.
+func goarch(arch string) string {
+	if arch == "arm64" {
+		return "arm64"
 	}
 
+	return "amd64"
+}
+
+// crystal runtime.
+var crystal = &runtime{
+	name:     RuntimeCrystal,
+	priority: 70,
+	files:    []string{"main.cr"},
+	dev:      "crystal run main.cr",
+	build:    hook(`docker run --rm -v $(pwd):/src -w /src crystallang/crystal crystal build -o server main.cr --release --static`),
+	clean:    hook(`rm server`),
+}
+
+// clojure lein runtime, assuming the uberjar is copied to server.jar.
+var clojureLein = &runtime{
+	name:     RuntimeClojure,
+	priority: 50,
+	files:    []string{"project.clj"},
+	command:  "java -jar server.jar",
+	build:    hook(`lein uberjar && cp target/*-standalone.jar server.jar`),
+	clean:    hook(`lein clean && rm server.jar`),
+}
+
+// java maven runtime, assuming package results in a shaded jar named server.jar.
+var javaMaven = &runtime{
+	name:     RuntimeJavaMaven,
+	priority: 40,
+	files:    []string{"pom.xml"},
+	command:  "java -jar server.jar",
+	build:    wrapper("mvnw", `./mvnw clean package && cp target/server.jar .`, `mvn clean package && cp target/server.jar .`),
+	clean:    hook(`rm server.jar && mvn clean`),
+}
+
+// java gradle runtime, assuming build results in a shaded jar named server.jar.
+var javaGradle = &runtime{
+	name:     RuntimeJavaGradle,
+	priority: 30,
+	files:    []string{"build.gradle"},
+	command:  "java -jar server.jar",
+	build:    wrapper("gradlew", `./gradlew clean build && cp build/libs/server.jar .`, `gradle clean build && cp build/libs/server.jar .`),
+	clean:    hook(`rm server.jar && gradle clean`),
+}
+
+// rust runtime, statically linked for Linux with musl.
+type rust struct{}
+
+// Name implementation.
+func (rust) Name() string {
+	return RuntimeRust
+}
+
+// Priority implementation.
+func (rust) Priority() int {
+	return 9
+}
+
+// Detect implementation.
+func (rust) Detect() bool {
+	return util.Exists("Cargo.toml")
+}
+
+// Command implementation.
+func (rust) Command() string {
+	return ""
+}
+
+// DevelopmentCommand implementation.
+func (rust) DevelopmentCommand() string {
+	return "cargo run"
+}
+
+// BuildHook implementation.
+func (r rust) BuildHook() Hook {
+	return r.build(defaultArchitecture)
+}
+
+// Configure targets the Lambda architecture.
+func (r rust) Configure(c *Config) error {
 	if c.Hooks.Build.IsEmpty() {
-		// assumes package results in a shaded jar named server.jar
-		c.Hooks.Build = Hook{`lein uberjar && cp target/*-standalone.jar server.jar`}
+		c.Hooks.Build = r.build(c.Lambda.Architecture)
 	}
 
-	if c.Hooks.Clean.IsEmpty() {
-		c.Hooks.Clean = Hook{`lein clean && rm server.jar`}
+	return nil
+}
+
+// build returns the build hook for the architecture.
+func (r rust) build(arch string) Hook {
+	target := targetArch(arch) + "-unknown-linux-musl"
+	return Hook{`cargo build --release --target ` + target + ` && cp target/` + target + `/release/` + r.binary() + ` server`}
+}
+
+// CleanHook implementation.
+func (rust) CleanHook() Hook {
+	return Hook{`rm server`}
+}
+
+// binary returns the name of the Cargo.toml package binary,
+// which defaults to the directory name as with `cargo new`.
+func (rust) binary() string {
+	var manifest struct {
+		Package struct {
+			Name string `toml:"name"`
+		} `toml:"package"`
+	}
+
+	if _, err := toml.DecodeFile("Cargo.toml", &manifest); err == nil && manifest.Package.Name != "" {
+		return manifest.Package.Name
 	}
+
+	dir, _ := os.Getwd()
+	return filepath.Base(dir)
+}
+
+// denoEntrypoints is a list of Deno entrypoints in order of precedence.
+var denoEntrypoints = []string{
+	"main.ts",
+	"main.js",
+	"mod.ts",
+	"server.ts",
+}
+
+// deno runtime, compiled to a self-contained Linux binary.
+type deno struct{}
+
+// Name implementation.
+func (deno) Name() string {
+	return RuntimeDeno
+}
+
+// Priority implementation.
+func (deno) Priority() int {
+	return 8
+}
+
+// Detect implementation.
+func (deno) Detect() bool {
+	return util.Exists("deno.json") || util.Exists("deno.jsonc")
+}
+
+// Command implementation.
+func (deno) Command() string {
+	return ""
 }
 
-// crystal config.
-func crystal(c *Config) {
+// DevelopmentCommand implementation.
+func (d deno) DevelopmentCommand() string {
+	return `deno run --allow-all --watch ` + d.entrypoint()
+}
+
+// BuildHook implementation.
+func (d deno) BuildHook() Hook {
+	return d.build(defaultArchitecture)
+}
+
+// Configure targets the Lambda architecture.
+func (d deno) Configure(c *Config) error {
 	if c.Hooks.Build.IsEmpty() {
-		c.Hooks.Build = Hook{`docker run --rm -v $(pwd):/src -w /src crystallang/crystal crystal build -o server main.cr --release --static`}
+		c.Hooks.Build = d.build(c.Lambda.Architecture)
 	}
 
-	if c.Hooks.Clean.IsEmpty() {
-		c.Hooks.Clean = Hook{`rm server`}
-	}
+	return nil
+}
 
-	if s := c.Stages.GetByName("development"); s != nil {
-		if s.Proxy.Command == "" {
-			s.Proxy.Command = "crystal run main.cr"
+// build returns the build hook for the architecture.
+func (d deno) build(arch string) Hook {
+	return Hook{`deno compile --allow-all --target ` + targetArch(arch) + `-unknown-linux-gnu --output server ` + d.entrypoint()}
+}
+
+// CleanHook implementation.
+func (deno) CleanHook() Hook {
+	return Hook{`rm server`}
+}
+
+// entrypoint returns the first entrypoint present, defaulting to main.ts.
+func (deno) entrypoint() string {
+	for _, name := range denoEntrypoints {
+		if util.Exists(name) {
+			return name
 		}
 	}
+
+	return denoEntrypoints[0]
+}
+
+// ruby runtime, with gems vendored into vendor/bundle.
+type ruby struct{}
+
+// Name implementation.
+func (ruby) Name() string {
+	return RuntimeRuby
+}
+
+// Priority implementation.
+func (ruby) Priority() int {
+	return 7
+}
+
+// Detect implementation.
+func (ruby) Detect() bool {
+	return util.Exists("Gemfile") || util.Exists("config.ru")
 }
 
-// nodejs config.
-func nodejs(c *Config) error {
-	var pkg struct {
-		Scripts struct {
-			Start string `json:"start"`
-			Build string `json:"build"`
-		} `json:"scripts"`
+// Command implementation.
+func (r ruby) Command() string {
+	return `BUNDLE_PATH=vendor/bundle BUNDLE_WITHOUT=development:test ` + r.DevelopmentCommand()
+}
+
+// DevelopmentCommand implementation.
+func (ruby) DevelopmentCommand() string {
+	if util.Exists("config.ru") {
+		return `bundle exec rackup -o 0.0.0.0 -p $PORT`
 	}
 
-	// read package.json
-	if err := util.ReadFileJSON("package.json", &pkg); err != nil && !os.IsNotExist(errors.Cause(err)) {
-		return err
+	return `bundle exec ruby app.rb`
+}
+
+// BuildHook builds native extensions for Linux using Docker.
+func (ruby) BuildHook() Hook {
+	return Hook{`docker run --rm -v $(pwd):/src -w /src -e BUNDLE_PATH=vendor/bundle -e BUNDLE_WITHOUT=development:test ruby bundle install`}
+}
+
+// CleanHook implementation.
+func (ruby) CleanHook() Hook {
+	return Hook{`rm -fr vendor/bundle`}
+}
+
+// php runtime, with dependencies vendored into vendor.
+type php struct{}
+
+// Name implementation.
+func (php) Name() string {
+	return RuntimePHP
+}
+
+// Priority implementation.
+func (php) Priority() int {
+	return 6
+}
+
+// Detect implementation.
+func (php) Detect() bool {
+	return util.Exists("composer.json") || util.Exists("index.php")
+}
+
+// Command implementation.
+func (p php) Command() string {
+	return p.DevelopmentCommand()
+}
+
+// DevelopmentCommand implementation.
+func (php) DevelopmentCommand() string {
+	if util.Exists("public") {
+		return `php -S 0.0.0.0:$PORT -t public`
 	}
 
-	// use "start" script unless explicitly defined in up.json
-	if c.Proxy.Command == "" {
-		if s := pkg.Scripts.Start; s == "" {
-			c.Proxy.Command = `node app.js`
-		} else {
-			c.Proxy.Command = s
+	return `php -S 0.0.0.0:$PORT`
+}
+
+// BuildHook implementation.
+func (php) BuildHook() Hook {
+	if !util.Exists("composer.json") {
+		return nil
+	}
+
+	return Hook{`composer install --no-dev --optimize-autoloader --ignore-platform-reqs`}
+}
+
+// CleanHook implementation.
+func (php) CleanHook() Hook {
+	return nil
+}
+
+// dotnet runtime, published as a self-contained Linux binary.
+type dotnet struct{}
+
+// Name implementation.
+func (dotnet) Name() string {
+	return RuntimeDotnet
+}
+
+// Priority implementation.
+func (dotnet) Priority() int {
+	return 5
+}
+
+// Detect implementation.
+func (dotnet) Detect() bool {
+	for _, pattern := range []string{"*.csproj", "*.fsproj"} {
+		if matches, _ := filepath.Glob(pattern); len(matches) > 0 {
+			return true
 		}
 	}
 
-	// use "build" script unless explicitly defined in up.json
+	return false
+}
+
+// Command implementation.
+func (dotnet) Command() string {
+	return `./server --urls http://0.0.0.0:$PORT`
+}
+
+// DevelopmentCommand implementation.
+func (dotnet) DevelopmentCommand() string {
+	return `dotnet run --urls http://0.0.0.0:$PORT`
+}
+
+// BuildHook implementation.
+func (d dotnet) BuildHook() Hook {
+	return d.build(defaultArchitecture)
+}
+
+// Configure targets the Lambda architecture.
+func (d dotnet) Configure(c *Config) error {
 	if c.Hooks.Build.IsEmpty() {
-		c.Hooks.Build = Hook{pkg.Scripts.Build}
+		c.Hooks.Build = d.build(c.Lambda.Architecture)
 	}
 
 	return nil
 }
 
-// python config.
-func python(c *Config) {
-	if c.Proxy.Command == "" {
-		c.Proxy.Command = "python app.py"
+// build returns the build hook for the architecture.
+func (dotnet) build(arch string) Hook {
+	rid := "linux-x64"
+	if arch == "arm64" {
+		rid = "linux-arm64"
 	}
 
-	// Only add build & clean hooks if a requirements.txt exists
-	if !util.Exists("requirements.txt") {
-		return
+	return Hook{`dotnet publish -c Release -r ` + rid + ` --self-contained -p:PublishSingleFile=true -p:AssemblyName=server -o .`}
+}
+
+// CleanHook implementation.
+func (dotnet) CleanHook() Hook {
+	return Hook{`rm server && dotnet clean`}
+}
+
+// nodejs runtime.
+type nodejs struct{}
+
+// Name implementation.
+func (nodejs) Name() string {
+	return RuntimeNode
+}
+
+// Priority implementation.
+func (nodejs) Priority() int {
+	return 60
+}
+
+// Detect implementation.
+func (nodejs) Detect() bool {
+	return util.Exists("package.json") || util.Exists("app.js")
+}
+
+// Command returns the "start" script of package.json, or
+// runs app.js from the TypeScript output directory if any.
+func (n nodejs) Command() string {
+	pkg := n.pkg()
+
+	if s := pkg.Scripts.Start; s != "" {
+		return s
+	}
+
+	if dir := n.outDir(); dir != "" {
+		return `node ` + filepath.Join(dir, "app.js")
+	}
+
+	return `node app.js`
+}
+
+// DevelopmentCommand runs the "dev" script of package.json when present.
+func (n nodejs) DevelopmentCommand() string {
+	if n.pkg().Scripts.Dev != "" {
+		return packageManager() + ` run dev`
+	}
+
+	return ""
+}
+
+// BuildHook runs the "build" script of package.json,
+// or compiles TypeScript when a tsconfig.json is present.
+func (n nodejs) BuildHook() Hook {
+	pm := packageManager()
+
+	if n.pkg().Scripts.Build != "" {
+		return Hook{pm + ` run build`}
+	}
+
+	if !util.Exists("tsconfig.json") {
+		return nil
+	}
+
+	switch pm {
+	case "npm":
+		return Hook{`npx tsc`}
+	case "pnpm":
+		return Hook{`pnpm exec tsc`}
+	default:
+		return Hook{pm + ` tsc`}
+	}
+}
+
+// CleanHook implementation.
+func (nodejs) CleanHook() Hook {
+	return nil
+}
+
+// Configure validates that package.json is readable.
+func (nodejs) Configure(c *Config) error {
+	var pkg packageJSON
+
+	if err := util.ReadFileJSON("package.json", &pkg); err != nil && !os.IsNotExist(errors.Cause(err)) {
+		return err
+	}
+
+	return nil
+}
+
+// packageJSON is the subset of package.json used.
+type packageJSON struct {
+	Scripts struct {
+		Start string `json:"start"`
+		Build string `json:"build"`
+		Dev   string `json:"dev"`
+	} `json:"scripts"`
+}
+
+// pkg returns the package.json, or an empty one when missing or malformed.
+func (nodejs) pkg() (pkg packageJSON) {
+	util.ReadFileJSON("package.json", &pkg)
+	return
+}
+
+// outDir returns the TypeScript compiler output directory, or an empty string.
+func (nodejs) outDir() string {
+	var tsconfig struct {
+		CompilerOptions struct {
+			OutDir string `json:"outDir"`
+		} `json:"compilerOptions"`
+	}
+
+	// tsconfig.json allows comments, which are not supported
+	util.ReadFileJSON("tsconfig.json", &tsconfig)
+	return tsconfig.CompilerOptions.OutDir
+}
+
+// packageManager returns the Node package manager based on the lockfile present.
+func packageManager() string {
+	switch {
+	case util.Exists("pnpm-lock.yaml"):
+		return "pnpm"
+	case util.Exists("yarn.lock"):
+		return "yarn"
+	default:
+		return "npm"
+	}
+}
+
+// python runtime, with dependencies vendored into .pypath/.
+type python struct{}
+
+// Name implementation.
+func (python) Name() string {
+	return RuntimePython
+}
+
+// Priority implementation.
+func (python) Priority() int {
+	return 20
+}
+
+// Detect implementation.
+func (python) Detect() bool {
+	return util.Exists("app.py")
+}
+
+// Command implementation.
+func (python) Command() string {
+	return "python app.py"
+}
+
+// DevelopmentCommand runs the app within the Poetry or Pipenv virtualenv.
+func (p python) DevelopmentCommand() string {
+	switch p.manager() {
+	case "poetry":
+		return "poetry run python app.py"
+	case "pipenv":
+		return "pipenv run python app.py"
+	default:
+		return ""
+	}
+}
+
+// BuildHook copies dependencies into .pypath/, exporting
+// them from Poetry or Pipenv when used.
+func (p python) BuildHook() Hook {
+	const install = `pip install -r .pypath/requirements.txt -t .pypath/`
+
+	switch p.manager() {
+	case "poetry":
+		return Hook{`mkdir -p .pypath/ && poetry export --without-hashes -o .pypath/requirements.txt && ` + install}
+	case "pipenv":
+		return Hook{`mkdir -p .pypath/ && pipenv requirements > .pypath/requirements.txt && ` + install}
+	case "pip":
+		return Hook{`mkdir -p .pypath/ && pip install -r requirements.txt -t .pypath/`}
+	case "pyproject":
+		return Hook{`mkdir -p .pypath/ && pip install . -t .pypath/`}
+	default:
+		return nil
+	}
+}
+
+// CleanHook removes .pypath/ when dependencies are present.
+func (p python) CleanHook() Hook {
+	if p.manager() == "" {
+		return nil
+	}
+
+	return Hook{`rm -r .pypath/`}
+}
+
+// Configure sets PYTHONPATH when dependencies are present.
+func (p python) Configure(c *Config) error {
+	if p.manager() == "" {
+		return nil
 	}
 
-	// Set PYTHONPATH env
 	if c.Environment == nil {
 		c.Environment = Environment{}
 	}
+
 	c.Environment["PYTHONPATH"] = ".pypath/"
+	return nil
+}
 
-	// Copy libraries into .pypath/
-	if c.Hooks.Build.IsEmpty() {
-		c.Hooks.Build = Hook{`mkdir -p .pypath/ && pip install -r requirements.txt -t .pypath/`}
+// manager returns the dependency manager used, or an empty string.
+func (python) manager() string {
+	switch {
+	case util.Exists("poetry.lock") || hasPoetry():
+		return "poetry"
+	case util.Exists("Pipfile"):
+		return "pipenv"
+	case util.Exists("requirements.txt"):
+		return "pip"
+	case util.Exists("pyproject.toml"):
+		return "pyproject"
+	default:
+		return ""
 	}
+}
 
-	// Clean .pypath/
-	if c.Hooks.Clean.IsEmpty() {
-		c.Hooks.Clean = Hook{`rm -r .pypath/`}
+// hasPoetry returns true if pyproject.toml has a [tool.poetry] table.
+func hasPoetry() bool {
+	var pyproject struct {
+		Tool struct {
+			Poetry map[string]interface{} `toml:"poetry"`
+		} `toml:"tool"`
+	}
+
+	if _, err := toml.DecodeFile("pyproject.toml", &pyproject); err != nil {
+		return false
 	}
+
+	return pyproject.Tool.Poetry != nil
+}
+
+// static runtime.
+type static struct{}
+
+// Name implementation.
+func (static) Name() string {
+	return RuntimeStatic
+}
+
+// Priority implementation.
+func (static) Priority() int {
+	return 10
+}
+
+// Detect implementation.
+func (static) Detect() bool {
+	return util.Exists("index.html")
+}
+
+// Command implementation.
+func (static) Command() string {
+	return ""
+}
+
+// DevelopmentCommand implementation.
+func (static) DevelopmentCommand() string {
+	return ""
+}
+
+// BuildHook implementation.
+func (static) BuildHook() Hook {
+	return nil
+}
+
+// CleanHook implementation.
+func (static) CleanHook() Hook {
+	return nil
+}
+
+// Configure sets the app type to static.
+func (static) Configure(c *Config) error {
+	c.Type = "static"
+	return nil
 }
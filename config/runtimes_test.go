@@ -0,0 +1,295 @@
+package config
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/tj/assert"
+)
+
+// chdir changes to a temporary directory containing files, returning a cleanup func.
+func chdir(t *testing.T, files ...string) func() {
+	dir, err := ioutil.TempDir("", "up-runtime")
+	assert.NoError(t, err, "tempdir")
+
+	cwd, err := os.Getwd()
+	assert.NoError(t, err, "getwd")
+	assert.NoError(t, os.Chdir(dir), "chdir")
+
+	for _, f := range files {
+		assert.NoError(t, ioutil.WriteFile(f, nil, 0644), "write")
+	}
+
+	return func() {
+		os.Chdir(cwd)
+		os.RemoveAll(dir)
+	}
+}
+
+// framework is a custom runtime.
+type framework struct{}
+
+func (framework) Name() string               { return "framework" }
+func (framework) Priority() int              { return 100 }
+func (framework) Detect() bool               { return fileExists("framework.toml") }
+func (framework) Command() string            { return "./framework serve" }
+func (framework) DevelopmentCommand() string { return "./framework dev" }
+func (framework) BuildHook() Hook            { return Hook{"framework build"} }
+func (framework) CleanHook() Hook            { return Hook{"framework clean"} }
+
+// fileExists returns true if the file exists.
+func fileExists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
+
+func TestRuntimes(t *testing.T) {
+	names := RuntimeNames()
+	assert.Equal(t, RuntimeGo, names[0])
+	assert.Equal(t, RuntimeDotnet, names[len(names)-1])
+}
+
+func TestConfig_runtime(t *testing.T) {
+	t.Run("inferred", func(t *testing.T) {
+		defer chdir(t, "main.go", "package.json")()
+
+		c, err := ParseConfigString(`{ "name": "app" }`)
+		assert.NoError(t, err, "parse")
+		assert.Equal(t, Hook{`GOOS=linux GOARCH=amd64 go build -o server *.go`}, c.Hooks.Build)
+		assert.Equal(t, Hook{`rm server`}, c.Hooks.Clean)
+		assert.Equal(t, "./server", c.Proxy.Command)
+		assert.Equal(t, "go run *.go", c.Stages.GetByName("development").Proxy.Command)
+	})
+
+	t.Run("inferred node", func(t *testing.T) {
+		defer chdir(t, "package.json", "Gemfile", "Cargo.toml", "composer.json", "deno.json", "api.csproj")()
+		assert.Equal(t, RuntimeNode, inferRuntime().Name())
+	})
+
+	t.Run("inferred existing runtimes", func(t *testing.T) {
+		cases := map[string][]string{
+			RuntimeStatic:    {"index.html", "Gemfile"},
+			RuntimeJavaMaven: {"pom.xml", "index.php"},
+			RuntimePython:    {"app.py", "Gemfile", "composer.json"},
+		}
+
+		for name, files := range cases {
+			func() {
+				defer chdir(t, files...)()
+				assert.Equal(t, name, inferRuntime().Name())
+			}()
+		}
+	})
+
+	t.Run("stage architecture", func(t *testing.T) {
+		defer chdir(t, "main.go")()
+
+		s := `{
+			"name": "app",
+			"stages": {
+				"staging": { "lambda": { "architecture": "arm64", "runtime": "nodejs20.x" } },
+				"production": { "hooks": { "build": "make" } }
+			}
+		}`
+
+		c := MustParseConfigString(s)
+		assert.Equal(t, Hook{`GOOS=linux GOARCH=amd64 go build -o server *.go`}, c.Hooks.Build)
+		assert.NoError(t, c.Override("staging"), "override")
+		assert.Equal(t, Hook{`GOOS=linux GOARCH=arm64 go build -o server *.go`}, c.Hooks.Build)
+
+		c = MustParseConfigString(s)
+		assert.NoError(t, c.Override("production"), "override")
+		assert.Equal(t, Hook{`make`}, c.Hooks.Build)
+	})
+
+	t.Run("specified", func(t *testing.T) {
+		defer chdir(t, "main.go")()
+
+		c, err := ParseConfigString(`{ "name": "app", "runtime": "python" }`)
+		assert.NoError(t, err, "parse")
+		assert.Equal(t, "python app.py", c.Proxy.Command)
+		assert.True(t, c.Hooks.Build.IsEmpty())
+	})
+
+	t.Run("unknown", func(t *testing.T) {
+		defer chdir(t)()
+
+		_, err := ParseConfigString(`{ "name": "app", "runtime": "cobol" }`)
+		assert.Contains(t, err.Error(), `defaulting: .runtime: "cobol" is invalid, must be one of:`)
+	})
+
+	t.Run("registered", func(t *testing.T) {
+		RegisterRuntime(framework{})
+		defer func() {
+			runtimes.Lock()
+			runtimes.list = runtimes.list[:len(runtimes.list)-1]
+			runtimes.Unlock()
+		}()
+
+		defer chdir(t, "main.go", "framework.toml")()
+
+		c, err := ParseConfigString(`{ "name": "app", "hooks": { "clean": "make clean" } }`)
+		assert.NoError(t, err, "parse")
+		assert.Equal(t, "./framework serve", c.Proxy.Command)
+		assert.Equal(t, Hook{"framework build"}, c.Hooks.Build)
+		assert.Equal(t, Hook{"make clean"}, c.Hooks.Clean)
+		assert.Equal(t, "./framework dev", c.Stages.GetByName("development").Proxy.Command)
+	})
+}
+
+func TestConfig_runtimes(t *testing.T) {
+	cases := []struct {
+		files   []string
+		command string
+		dev     string
+		build   Hook
+	}{
+		{
+			files:   []string{"Gemfile", "config.ru"},
+			command: `BUNDLE_PATH=vendor/bundle BUNDLE_WITHOUT=development:test bundle exec rackup -o 0.0.0.0 -p $PORT`,
+			dev:     `bundle exec rackup -o 0.0.0.0 -p $PORT`,
+			build:   Hook{`docker run --rm -v $(pwd):/src -w /src -e BUNDLE_PATH=vendor/bundle -e BUNDLE_WITHOUT=development:test ruby bundle install`},
+		},
+		{
+			files:   []string{"Cargo.toml"},
+			command: `./server`,
+			dev:     `cargo run`,
+			build:   Hook{`cargo build --release --target x86_64-unknown-linux-musl && cp target/x86_64-unknown-linux-musl/release/api server`},
+		},
+		{
+			files:   []string{"composer.json", "public"},
+			command: `php -S 0.0.0.0:$PORT -t public`,
+			dev:     `php -S 0.0.0.0:$PORT -t public`,
+			build:   Hook{`composer install --no-dev --optimize-autoloader --ignore-platform-reqs`},
+		},
+		{
+			files:   []string{"deno.json", "server.ts"},
+			command: `./server`,
+			dev:     `deno run --allow-all --watch server.ts`,
+			build:   Hook{`deno compile --allow-all --target x86_64-unknown-linux-gnu --output server server.ts`},
+		},
+		{
+			files:   []string{"api.csproj"},
+			command: `./server --urls http://0.0.0.0:$PORT`,
+			dev:     `dotnet run --urls http://0.0.0.0:$PORT`,
+			build:   Hook{`dotnet publish -c Release -r linux-x64 --self-contained -p:PublishSingleFile=true -p:AssemblyName=server -o .`},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.files[0], func(t *testing.T) {
+			defer chdir(t, c.files...)()
+
+			if c.files[0] == "Cargo.toml" {
+				assert.NoError(t, ioutil.WriteFile("Cargo.toml", []byte("[package]\nname = \"api\"\n"), 0644))
+			}
+
+			conf, err := ParseConfigString(`{ "name": "app" }`)
+			assert.NoError(t, err, "parse")
+			assert.Equal(t, c.command, conf.Proxy.Command)
+			assert.Equal(t, c.dev, conf.Stages.GetByName("development").Proxy.Command)
+			assert.Equal(t, c.build, conf.Hooks.Build)
+		})
+	}
+}
+
+func TestConfig_runtimeLayouts(t *testing.T) {
+	cases := []struct {
+		name    string
+		config  string
+		files   map[string]string
+		command string
+		dev     string
+		build   Hook
+	}{
+		{
+			name:    "go module cmd",
+			config:  `{ "name": "api", "lambda": { "architecture": "arm64", "runtime": "nodejs20.x" } }`,
+			files:   map[string]string{"go.mod": "module api", "cmd/worker/main.go": "", "cmd/api/main.go": ""},
+			command: `./server`,
+			dev:     `go run ./cmd/api`,
+			build:   Hook{`GOOS=linux GOARCH=arm64 go build -o server ./cmd/api`},
+		},
+		{
+			name:    "go module root",
+			config:  `{ "name": "app" }`,
+			files:   map[string]string{"go.mod": "module app", "main.go": ""},
+			command: `./server`,
+			dev:     `go run .`,
+			build:   Hook{`GOOS=linux GOARCH=amd64 go build -o server .`},
+		},
+		{
+			name:    "go in-process",
+			config:  `{ "name": "app", "type": "go" }`,
+			files:   map[string]string{"go.mod": "module app", "main.go": ""},
+			command: `./main`,
+			dev:     `go run .`,
+			build:   Hook{`GOOS=linux GOARCH=amd64 go build -o main .`},
+		},
+		{
+			name:    "rust arm64",
+			config:  `{ "name": "app", "lambda": { "architecture": "arm64", "runtime": "nodejs20.x" } }`,
+			files:   map[string]string{"Cargo.toml": "[package]\nname = \"api\"\n"},
+			command: `./server`,
+			dev:     `cargo run`,
+			build:   Hook{`cargo build --release --target aarch64-unknown-linux-musl && cp target/aarch64-unknown-linux-musl/release/api server`},
+		},
+		{
+			name:    "yarn",
+			config:  `{ "name": "app" }`,
+			files:   map[string]string{"package.json": `{ "scripts": { "start": "node server.js", "build": "webpack", "dev": "nodemon" } }`, "yarn.lock": ""},
+			command: `node server.js`,
+			dev:     `yarn run dev`,
+			build:   Hook{`yarn run build`},
+		},
+		{
+			name:    "typescript",
+			config:  `{ "name": "app" }`,
+			files:   map[string]string{"package.json": `{}`, "tsconfig.json": `{ "compilerOptions": { "outDir": "dist" } }`, "pnpm-lock.yaml": ""},
+			command: `node dist/app.js`,
+			build:   Hook{`pnpm exec tsc`},
+		},
+		{
+			name:    "poetry",
+			config:  `{ "name": "app" }`,
+			files:   map[string]string{"app.py": "", "pyproject.toml": "[tool.poetry]\nname = \"app\"\n"},
+			command: `python app.py`,
+			dev:     `poetry run python app.py`,
+			build:   Hook{`mkdir -p .pypath/ && poetry export --without-hashes -o .pypath/requirements.txt && pip install -r .pypath/requirements.txt -t .pypath/`},
+		},
+		{
+			name:    "pipenv",
+			config:  `{ "name": "app" }`,
+			files:   map[string]string{"app.py": "", "Pipfile": ""},
+			command: `python app.py`,
+			dev:     `pipenv run python app.py`,
+			build:   Hook{`mkdir -p .pypath/ && pipenv requirements > .pypath/requirements.txt && pip install -r .pypath/requirements.txt -t .pypath/`},
+		},
+		{
+			name:    "pyproject",
+			config:  `{ "name": "app" }`,
+			files:   map[string]string{"app.py": "", "pyproject.toml": "[project]\nname = \"app\"\n"},
+			command: `python app.py`,
+			build:   Hook{`mkdir -p .pypath/ && pip install . -t .pypath/`},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			defer chdir(t)()
+
+			for name, s := range c.files {
+				assert.NoError(t, os.MkdirAll(filepath.Dir(name), 0755), "mkdir")
+				assert.NoError(t, ioutil.WriteFile(name, []byte(s), 0644), "write")
+			}
+
+			conf, err := ParseConfigString(c.config)
+			assert.NoError(t, err, "parse")
+			assert.Equal(t, c.command, conf.Proxy.Command)
+			assert.Equal(t, c.dev, conf.Stages.GetByName("development").Proxy.Command)
+			assert.Equal(t, c.build, conf.Hooks.Build)
+		})
+	}
+}
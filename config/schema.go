@@ -0,0 +1,104 @@
+package config
+
+import (
+	"reflect"
+	"strings"
+)
+
+// schemer is the interface implemented by types with custom
+// JSON unmarshaling, providing their own JSON Schema.
+type schemer interface {
+	JSONSchema() map[string]interface{}
+}
+
+// schemerType is the reflect type of schemer.
+var schemerType = reflect.TypeOf((*schemer)(nil)).Elem()
+
+// Schema returns the JSON Schema of the config, generated from its types.
+func Schema() map[string]interface{} {
+	s := schemaOf(reflect.TypeOf(Config{}))
+
+	props := s["properties"].(map[string]interface{})
+	props["runtime"] = map[string]interface{}{
+		"type": "string",
+		"enum": RuntimeNames(),
+	}
+
+	// allows up.json to reference the schema for editor support
+	props["$schema"] = map[string]interface{}{
+		"type": "string",
+	}
+
+	s["$schema"] = "http://json-schema.org/draft-07/schema#"
+	s["title"] = "Up configuration"
+	return s
+}
+
+// schemaOf returns the JSON Schema for type t.
+func schemaOf(t reflect.Type) map[string]interface{} {
+	if reflect.PtrTo(t).Implements(schemerType) {
+		return reflect.New(t).Interface().(schemer).JSONSchema()
+	}
+
+	switch t.Kind() {
+	case reflect.Ptr:
+		return schemaOf(t.Elem())
+	case reflect.String:
+		return map[string]interface{}{"type": "string"}
+	case reflect.Bool:
+		return map[string]interface{}{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]interface{}{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		return map[string]interface{}{"type": "number"}
+	case reflect.Slice, reflect.Array:
+		return map[string]interface{}{
+			"type":  "array",
+			"items": schemaOf(t.Elem()),
+		}
+	case reflect.Map:
+		return map[string]interface{}{
+			"type":                 "object",
+			"additionalProperties": schemaOf(t.Elem()),
+		}
+	case reflect.Struct:
+		props := make(map[string]interface{})
+		structProperties(t, props)
+		return map[string]interface{}{
+			"type":                 "object",
+			"properties":           props,
+			"additionalProperties": false,
+		}
+	default:
+		return map[string]interface{}{}
+	}
+}
+
+// structProperties adds the properties of struct t to props,
+// flattening embedded structs as encoding/json does.
+func structProperties(t reflect.Type, props map[string]interface{}) {
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+
+		if f.PkgPath != "" {
+			continue
+		}
+
+		if f.Anonymous {
+			structProperties(f.Type, props)
+			continue
+		}
+
+		name := strings.Split(f.Tag.Get("json"), ",")[0]
+		if name == "-" {
+			continue
+		}
+
+		if name == "" {
+			name = f.Name
+		}
+
+		props[name] = schemaOf(f.Type)
+	}
+}
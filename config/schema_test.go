@@ -0,0 +1,38 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/tj/assert"
+)
+
+func TestSchema(t *testing.T) {
+	s := Schema()
+	props := s["properties"].(map[string]interface{})
+
+	assert.Equal(t, "object", s["type"])
+	assert.Equal(t, false, s["additionalProperties"])
+	assert.Equal(t, map[string]interface{}{"type": "string"}, props["name"])
+	assert.Equal(t, map[string]interface{}{"type": "string"}, props["$schema"])
+
+	t.Run("custom unmarshalers", func(t *testing.T) {
+		hooks := props["hooks"].(map[string]interface{})["properties"].(map[string]interface{})
+		assert.Len(t, hooks["build"].(map[string]interface{})["oneOf"], 2)
+
+		dns := props["dns"].(map[string]interface{})
+		assert.Equal(t, "array", dns["additionalProperties"].(map[string]interface{})["type"])
+
+		var dur Duration
+		d := dur.JSONSchema()
+		assert.Len(t, d["oneOf"], 2)
+	})
+
+	t.Run("stage overrides", func(t *testing.T) {
+		stage := props["stages"].(map[string]interface{})["additionalProperties"].(map[string]interface{})
+		stageProps := stage["properties"].(map[string]interface{})
+		assert.Contains(t, stageProps, "domain")
+		assert.Contains(t, stageProps, "lambda")
+		assert.NotContains(t, stageProps, "StageOverrides")
+		assert.NotContains(t, stageProps, "Name")
+	})
+}
@@ -0,0 +1,97 @@
+package config
+
+import (
+	"github.com/pkg/errors"
+
+	"github.com/apex/up/internal/validate"
+)
+
+// restartPolicies is a list of supported sidecar restart policies.
+var restartPolicies = []string{
+	"always",
+	"on-failure",
+	"never",
+}
+
+// Sidecar config for a companion process run alongside the app.
+type Sidecar struct {
+	// Name of the sidecar, included in its logs.
+	Name string `json:"name"`
+
+	// Command run through the shell to start the sidecar.
+	Command string `json:"command"`
+
+	// Environment variables of the sidecar.
+	Environment Environment `json:"environment"`
+
+	// Port the sidecar listens on, waited for before starting the app.
+	Port int `json:"port"`
+
+	// Restart policy applied when the sidecar exits.
+	Restart string `json:"restart"`
+}
+
+// Default implementation.
+func (s *Sidecar) Default() error {
+	if s.Restart == "" {
+		s.Restart = "on-failure"
+	}
+
+	return nil
+}
+
+// Validate implementation.
+func (s *Sidecar) Validate() error {
+	if err := validate.Name(s.Name); err != nil {
+		return errors.Wrap(err, ".name")
+	}
+
+	if err := validate.RequiredString(s.Command); err != nil {
+		return errors.Wrap(err, ".command")
+	}
+
+	if s.Port != 0 {
+		if err := validate.Range(s.Port, 1, 65535); err != nil {
+			return errors.Wrap(err, ".port")
+		}
+	}
+
+	if err := validate.List(s.Restart, restartPolicies); err != nil {
+		return errors.Wrap(err, ".restart")
+	}
+
+	return nil
+}
+
+// Sidecars config.
+type Sidecars []Sidecar
+
+// Default implementation.
+func (s Sidecars) Default() error {
+	for i := range s {
+		if err := s[i].Default(); err != nil {
+			return errors.Wrapf(err, "sidecar %d", i)
+		}
+	}
+
+	return nil
+}
+
+// Validate implementation.
+func (s Sidecars) Validate() error {
+	names := make(map[string]bool)
+
+	for i, v := range s {
+		if err := v.Validate(); err != nil {
+			return errors.Wrapf(err, "sidecar %d", i)
+		}
+
+		if names[v.Name] {
+			return errors.Errorf("sidecar %d: .name: %q is used by another sidecar", i, v.Name)
+		}
+
+		names[v.Name] = true
+	}
+
+	return nil
+}
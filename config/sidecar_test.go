@@ -0,0 +1,46 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/tj/assert"
+)
+
+func TestSidecars_Validate(t *testing.T) {
+	t.Run("valid", func(t *testing.T) {
+		s := Sidecars{
+			{Name: "redis", Command: "redis-server", Port: 6379},
+			{Name: "poller", Command: "./poller"},
+		}
+
+		assert.NoError(t, s.Default(), "default")
+		assert.NoError(t, s.Validate())
+		assert.Equal(t, "on-failure", s[1].Restart)
+	})
+
+	t.Run("invalid name", func(t *testing.T) {
+		s := Sidecars{{Name: "Redis", Command: "redis-server"}}
+		assert.NoError(t, s.Default(), "default")
+		assert.EqualError(t, s.Validate(), `sidecar 0: .name: must contain only lowercase alphanumeric characters and '-'`)
+	})
+
+	t.Run("invalid restart", func(t *testing.T) {
+		s := Sidecars{{Name: "redis", Command: "redis-server", Restart: "sometimes"}}
+		assert.NoError(t, s.Default(), "default")
+		assert.EqualError(t, s.Validate(), `sidecar 0: .restart: "sometimes" is invalid, must be one of:
+
+  • always
+  • on-failure
+  • never`)
+	})
+
+	t.Run("duplicate name", func(t *testing.T) {
+		s := Sidecars{
+			{Name: "redis", Command: "redis-server"},
+			{Name: "redis", Command: "redis-server"},
+		}
+
+		assert.NoError(t, s.Default(), "default")
+		assert.EqualError(t, s.Validate(), `sidecar 1: .name: "redis" is used by another sidecar`)
+	})
+}
@@ -3,8 +3,12 @@ package config
 import (
 	"sort"
 
-	"github.com/apex/up/internal/validate"
 	"github.com/pkg/errors"
+
+	"github.com/apex/up/internal/header"
+	"github.com/apex/up/internal/inject"
+	"github.com/apex/up/internal/redirect"
+	"github.com/apex/up/internal/validate"
 )
 
 // defaultStages is a list of default stage names.
@@ -20,6 +24,7 @@ type Stage struct {
 	Zone   interface{} `json:"zone"`
 	Path   string      `json:"path"`
 	Cert   string      `json:"cert"`
+	ALB    *ALB        `json:"alb"`
 	Name   string      `json:"-"`
 	StageOverrides
 }
@@ -42,10 +47,22 @@ func (s *Stage) Validate() error {
 
 	switch s.Zone.(type) {
 	case bool, string:
-		return nil
 	default:
 		return errors.Errorf(".zone is an invalid type, must be string or boolean")
 	}
+
+	if s.ALB != nil {
+		if err := s.ALB.Validate(); err != nil {
+			return errors.Wrap(err, ".alb")
+		}
+	}
+
+	// reserved concurrency applies to the function shared by all stages
+	if s.Lambda.ReservedConcurrency != 0 {
+		return errors.New(".lambda.reserved_concurrency: may only be specified at the top-level, as it applies to all stages")
+	}
+
+	return nil
 }
 
 // Default implementation.
@@ -54,14 +71,33 @@ func (s *Stage) Default() error {
 		s.Zone = true
 	}
 
+	if s.ALB != nil {
+		if err := s.ALB.Default(); err != nil {
+			return errors.Wrap(err, ".alb")
+		}
+	}
+
 	return nil
 }
 
 // StageOverrides config.
+//
+// Maps such as .environment or .headers are merged
+// with the top-level config, while lists and scalar
+// values replace their counterpart when present.
 type StageOverrides struct {
-	Hooks  Hooks  `json:"hooks"`
-	Lambda Lambda `json:"lambda"`
-	Proxy  Relay  `json:"proxy"`
+	Hooks       Hooks               `json:"hooks"`
+	Lambda      Lambda              `json:"lambda"`
+	Proxy       RelayOverrides      `json:"proxy"`
+	Environment Environment         `json:"environment"`
+	Headers     header.Rules        `json:"headers"`
+	Redirects   redirect.Rules      `json:"redirects"`
+	CORS        *CORSOverrides      `json:"cors"`
+	ErrorPages  ErrorPagesOverrides `json:"error_pages"`
+	Static      Static              `json:"static"`
+	Logs        LogsOverrides       `json:"logs"`
+	Inject      inject.Rules        `json:"inject"`
+	Credentials
 }
 
 // Override config.
@@ -69,6 +105,32 @@ func (s *StageOverrides) Override(c *Config) {
 	s.Hooks.Override(c)
 	s.Lambda.Override(c)
 	s.Proxy.Override(c)
+	s.Environment.Override(c)
+	s.CORS.Override(c)
+	s.ErrorPages.Override(c)
+	s.Static.Override(c)
+	s.Logs.Override(c)
+	s.Credentials.Override(c)
+
+	if len(s.Headers) > 0 {
+		c.Headers = header.Merge(c.Headers, s.Headers)
+	}
+
+	if len(s.Redirects) > 0 && c.Redirects == nil {
+		c.Redirects = make(redirect.Rules)
+	}
+
+	for path, rule := range s.Redirects {
+		c.Redirects[path] = rule
+	}
+
+	if len(s.Inject) > 0 && c.Inject == nil {
+		c.Inject = make(inject.Rules)
+	}
+
+	for pos, rules := range s.Inject {
+		c.Inject[pos] = rules
+	}
 }
 
 // Stages config.
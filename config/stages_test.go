@@ -4,6 +4,8 @@ import (
 	"testing"
 
 	"github.com/tj/assert"
+
+	"github.com/apex/up/internal/header"
 )
 
 func TestStage_Override(t *testing.T) {
@@ -50,6 +52,156 @@ func TestStage_Override(t *testing.T) {
 	assert.Equal(t, `node app.js --foo=bar`, c.Proxy.Command)
 }
 
+func TestStage_Override_sections(t *testing.T) {
+	s := `{
+		"name": "app",
+		"regions": ["us-west-2"],
+		"environment": {
+			"API_URL": "https://api.example.com",
+			"LOG_LEVEL": "info"
+		},
+		"headers": {
+			"/*": {
+				"X-Frame-Options": "DENY"
+			}
+		},
+		"cors": {
+			"allowed_origins": ["https://example.com"],
+			"max_age": 60
+		},
+		"logs": {
+			"stdout": "info"
+		},
+		"stages": {
+			"staging": {
+				"environment": {
+					"API_URL": "https://api.staging.example.com"
+				},
+				"headers": {
+					"/*": {
+						"X-Robots-Tag": "noindex"
+					}
+				},
+				"cors": {
+					"allowed_origins": ["https://staging.example.com", "http://localhost:3000"]
+				},
+				"logs": {
+					"stdout": "debug"
+				}
+			},
+			"production": {
+				"inject": {
+					"head": [
+						{
+							"type": "google analytics",
+							"value": "UA-123"
+						}
+					]
+				},
+				"lambda": {
+					"policy": [
+						{
+							"Effect": "Allow",
+							"Resource": "*",
+							"Action": ["s3:GetObject"]
+						}
+					]
+				}
+			}
+		}
+	}`
+
+	t.Run("staging", func(t *testing.T) {
+		c := MustParseConfigString(s)
+		assert.NoError(t, c.Override("staging"), "override")
+		assert.Equal(t, Environment{"API_URL": "https://api.staging.example.com", "LOG_LEVEL": "info"}, c.Environment)
+		assert.Equal(t, "DENY", c.Headers["/*"]["X-Frame-Options"])
+		assert.Equal(t, "noindex", c.Headers["/*"]["X-Robots-Tag"])
+		assert.Equal(t, []string{"https://staging.example.com", "http://localhost:3000"}, c.CORS.AllowedOrigins)
+		assert.Equal(t, 60, c.CORS.MaxAge)
+		assert.Equal(t, "debug", c.Logs.Stdout)
+		assert.Equal(t, "error", c.Logs.Stderr)
+		assert.Empty(t, c.Inject)
+	})
+
+	t.Run("production", func(t *testing.T) {
+		c := MustParseConfigString(s)
+		assert.NoError(t, c.Override("production"), "override")
+		assert.Equal(t, Environment{"API_URL": "https://api.example.com", "LOG_LEVEL": "info"}, c.Environment)
+		assert.Equal(t, header.Fields{"X-Frame-Options": "DENY"}, c.Headers["/*"])
+		assert.Len(t, c.Inject["head"], 1)
+		assert.Equal(t, "google analytics", c.Inject["head"][0].Type)
+		assert.Equal(t, "info", c.Logs.Stdout)
+		assert.Len(t, c.Lambda.Policy, 2)
+		assert.Equal(t, defaultPolicy, c.Lambda.Policy[1])
+	})
+}
+
+func TestStage_Override_booleans(t *testing.T) {
+	s := `{
+		"name": "app",
+		"regions": ["us-west-2"],
+		"cors": {
+			"allow_credentials": true,
+			"max_age": 60
+		},
+		"error_pages": {
+			"enable": true
+		},
+		"logs": {
+			"disable": true
+		},
+		"proxy": {
+			"socket": true
+		},
+		"stages": {
+			"staging": {
+				"cors": {
+					"allow_credentials": false,
+					"max_age": 0,
+					"debug": true
+				},
+				"proxy": {
+					"socket": false
+				},
+				"error_pages": {
+					"enable": false
+				},
+				"logs": {
+					"disable": false
+				}
+			},
+			"production": {
+				"logs": {
+					"stdout": "warn"
+				}
+			}
+		}
+	}`
+
+	t.Run("staging", func(t *testing.T) {
+		c := MustParseConfigString(s)
+		assert.NoError(t, c.Override("staging"), "override")
+		assert.False(t, c.CORS.AllowCredentials)
+		assert.Equal(t, 0, c.CORS.MaxAge)
+		assert.True(t, c.CORS.Debug)
+		assert.False(t, c.ErrorPages.Enable)
+		assert.False(t, c.Logs.Disable)
+		assert.False(t, c.Proxy.Socket)
+	})
+
+	t.Run("production", func(t *testing.T) {
+		c := MustParseConfigString(s)
+		assert.NoError(t, c.Override("production"), "override")
+		assert.True(t, c.CORS.AllowCredentials)
+		assert.Equal(t, 60, c.CORS.MaxAge)
+		assert.True(t, c.ErrorPages.Enable)
+		assert.True(t, c.Logs.Disable)
+		assert.True(t, c.Proxy.Socket)
+		assert.Equal(t, "warn", c.Logs.Stdout)
+	})
+}
+
 func TestStages_Default(t *testing.T) {
 	t.Run("no custom stages", func(t *testing.T) {
 		s := Stages{}
@@ -133,6 +285,24 @@ func TestStages_Validate(t *testing.T) {
 		assert.NoError(t, s.Default(), "default")
 		assert.EqualError(t, s.Validate(), `stage "production": .zone is an invalid type, must be string or boolean`)
 	})
+
+	t.Run("reserved concurrency", func(t *testing.T) {
+		s := Stages{
+			"production": &Stage{
+				StageOverrides: StageOverrides{
+					Lambda: Lambda{ReservedConcurrency: 0},
+				},
+			},
+			"staging": &Stage{
+				StageOverrides: StageOverrides{
+					Lambda: Lambda{ReservedConcurrency: 10},
+				},
+			},
+		}
+
+		assert.NoError(t, s.Default(), "default")
+		assert.EqualError(t, s.Validate(), `stage "staging": .lambda.reserved_concurrency: may only be specified at the top-level, as it applies to all stages`)
+	})
 }
 
 func TestStages_List(t *testing.T) {
@@ -33,3 +33,14 @@ func (s *Static) Validate() error {
 
 	return nil
 }
+
+// Override config.
+func (s *Static) Override(c *Config) {
+	if s.Dir != "" {
+		c.Static.Dir = s.Dir
+	}
+
+	if s.Prefix != "" {
+		c.Static.Prefix = s.Prefix
+	}
+}
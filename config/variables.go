@@ -0,0 +1,398 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ssm"
+	"github.com/pkg/errors"
+	"github.com/tj/go/git"
+
+	"github.com/apex/up/internal/util"
+)
+
+// namespaces of variables such as ${env:NAME}, references
+// such as ${PORT} are left as-is for use by shell commands.
+var namespaces = []string{"env:", "git:", "ssm:"}
+
+// stagesType is used to skip stages when interpolating top-level config.
+var stagesType = reflect.TypeOf(Stages(nil))
+
+// variables resolves ${...} placeholders in string values.
+//
+// The following variables are supported:
+//
+//   ${stage}         name of the target stage
+//   ${env:NAME}      environment variable NAME
+//   ${git:commit}    git tag or abbreviated commit sha
+//   ${ssm:/path}     decrypted SSM parameter value
+//
+// Each value is resolved once. Stage values are resolved only for the
+// target stage, and top-level values referencing ${stage} or parameters
+// are deferred until the stage, and so its credentials, are known.
+type variables struct {
+	// stage name, when empty values referencing ${stage} are deferred.
+	stage string
+
+	// config used for the region of the ssm client.
+	config *Config
+
+	// creds of the target stage used by the ssm client.
+	creds Credentials
+
+	// cache of resolved values.
+	cache map[string]string
+
+	// deployed is true when the cache is used in place of the environment
+	// and git, which are unavailable to the deployed function. Parameters
+	// are fetched by the function using its own role.
+	deployed bool
+
+	// offline is true when network resolution is disabled, leaving
+	// ${ssm:...} references as-is, for example when linting.
+	offline bool
+
+	// environless is true when ${env:...} references are left as-is,
+	// for example when linting stages not targeted by a deploy.
+	environless bool
+
+	// interpolated is true once the config is interpolated.
+	interpolated bool
+
+	// done is the set of resolved paths.
+	done map[string]bool
+
+	// pending is the set of deferred paths.
+	pending map[string]bool
+
+	// only restricts the walk to the given paths.
+	only map[string]bool
+
+	// errors prefixed with their JSON path.
+	errors []error
+}
+
+// newVariables returns variables for the given config.
+func newVariables(c *Config) *variables {
+	return &variables{
+		config:  c,
+		cache:   make(map[string]string),
+		done:    make(map[string]bool),
+		pending: make(map[string]bool),
+	}
+}
+
+// Err returns the first resolution error.
+func (v *variables) Err() error {
+	if len(v.errors) == 0 {
+		return nil
+	}
+
+	return v.errors[0]
+}
+
+// interpolate the top-level config in place, recording
+// errors for unresolvable references. Stages are left
+// as-is until targeted, see interpolateStage.
+func (v *variables) interpolate(c *Config) {
+	if v.interpolated {
+		return
+	}
+
+	v.walk("", reflect.ValueOf(c).Elem())
+	v.interpolated = true
+}
+
+// interpolateStage resolves the values of stage s, and the top-level
+// values deferred until the stage is known. Parameters are fetched
+// with the credentials of the stage, so they're resolved first.
+func (v *variables) interpolateStage(c *Config, s *Stage) {
+	path := ".stages." + s.Name
+
+	t := *v
+	t.stage = s.Name
+
+	t.only = v.pending
+	t.walk("", reflect.ValueOf(&c.Credentials).Elem())
+	t.only = nil
+	t.walk(path, reflect.ValueOf(&s.Credentials).Elem())
+	t.creds = s.Credentials.override(c.Credentials)
+
+	t.walk(path, reflect.ValueOf(s).Elem())
+	t.only = v.pending
+	t.walk("", reflect.ValueOf(c).Elem())
+
+	v.errors = t.errors
+	v.pending = make(map[string]bool)
+}
+
+// deferred returns true if s must be resolved once the stage is known.
+func deferred(s string) bool {
+	return strings.Contains(s, "${stage}") || strings.Contains(s, "${ssm:")
+}
+
+// walk value v at the given JSON path.
+func (v *variables) walk(path string, val reflect.Value) {
+	switch val.Kind() {
+	case reflect.String:
+		v.walkString(path, val)
+	case reflect.Ptr:
+		if !val.IsNil() {
+			v.walk(path, val.Elem())
+		}
+	case reflect.Interface:
+		if val.IsNil() {
+			return
+		}
+		e := reflect.New(val.Elem().Type()).Elem()
+		e.Set(val.Elem())
+		v.walk(path, e)
+		val.Set(e)
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < val.Len(); i++ {
+			v.walk(fmt.Sprintf("%s[%d]", path, i), val.Index(i))
+		}
+	case reflect.Map:
+		keys := val.MapKeys()
+		sort.Slice(keys, func(i, j int) bool {
+			return fmt.Sprint(keys[i]) < fmt.Sprint(keys[j])
+		})
+
+		for _, k := range keys {
+			e := reflect.New(val.Type().Elem()).Elem()
+			e.Set(val.MapIndex(k))
+			v.walk(fmt.Sprintf("%s.%v", path, k), e)
+			val.SetMapIndex(k, e)
+		}
+	case reflect.Struct:
+		t := val.Type()
+		for i := 0; i < t.NumField(); i++ {
+			f := t.Field(i)
+
+			if f.PkgPath != "" || f.Type == stagesType {
+				continue
+			}
+
+			if f.Anonymous {
+				v.walk(path, val.Field(i))
+				continue
+			}
+
+			name := strings.Split(f.Tag.Get("json"), ",")[0]
+			if name == "-" {
+				continue
+			}
+
+			if name == "" {
+				name = f.Name
+			}
+
+			v.walk(path+"."+name, val.Field(i))
+		}
+	}
+}
+
+// walkString resolves the string value at path unless it was resolved,
+// deferring values which reference ${stage} or parameters until the
+// stage is known.
+func (v *variables) walkString(path string, val reflect.Value) {
+	if v.done[path] || (v.only != nil && !v.only[path]) {
+		return
+	}
+
+	s := val.String()
+
+	if v.stage == "" && deferred(s) {
+		v.pending[path] = true
+		return
+	}
+
+	v.done[path] = true
+
+	s, err := v.expand(s)
+	if err != nil {
+		v.errors = append(v.errors, errors.Wrap(err, path))
+		return
+	}
+
+	val.SetString(s)
+}
+
+// expand replaces variables in s in a single pass, resolving nested
+// references first. Resolved values are never expanded again.
+func (v *variables) expand(s string) (string, error) {
+	var b strings.Builder
+
+	for {
+		i := strings.Index(s, "${")
+		if i == -1 {
+			break
+		}
+
+		j := closingBrace(s, i+2)
+		if j == -1 {
+			break
+		}
+
+		name, err := v.expand(s[i+2 : j])
+		if err != nil {
+			return "", err
+		}
+
+		b.WriteString(s[:i])
+		s = s[j+1:]
+
+		if !isVariable(name) {
+			b.WriteString("${" + name + "}")
+			continue
+		}
+
+		val, err := v.resolve(name)
+		if err != nil {
+			return "", err
+		}
+
+		b.WriteString(val)
+	}
+
+	b.WriteString(s)
+	return b.String(), nil
+}
+
+// closingBrace returns the index of the brace closing
+// the reference starting at i, or -1 when unterminated.
+func closingBrace(s string, i int) int {
+	depth := 0
+
+	for ; i < len(s); i++ {
+		switch {
+		case strings.HasPrefix(s[i:], "${"):
+			depth++
+			i++
+		case s[i] == '}' && depth == 0:
+			return i
+		case s[i] == '}':
+			depth--
+		}
+	}
+
+	return -1
+}
+
+// isVariable returns true if name is a supported variable.
+func isVariable(name string) bool {
+	if name == "stage" {
+		return true
+	}
+
+	if strings.Contains(name, "${") {
+		return false
+	}
+
+	for _, ns := range namespaces {
+		if strings.HasPrefix(name, ns) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// resolve variable name.
+func (v *variables) resolve(name string) (string, error) {
+	if name == "stage" {
+		return v.stage, nil
+	}
+
+	if s, ok := v.cache[name]; ok {
+		return s, nil
+	}
+
+	kind, arg := name, ""
+	if i := strings.Index(name, ":"); i != -1 {
+		kind, arg = name[:i], name[i+1:]
+	}
+
+	if (v.offline && kind == "ssm") || (v.environless && kind == "env") {
+		return "${" + name + "}", nil
+	}
+
+	if v.deployed && kind != "ssm" {
+		return "", errors.Errorf("variable %q was not resolved when deploying", "${"+name+"}")
+	}
+
+	var s string
+	var err error
+
+	switch kind {
+	case "env":
+		s, err = resolveEnv(arg)
+	case "git":
+		s, err = resolveGit(arg)
+	case "ssm":
+		s, err = v.resolveSSM(arg)
+	}
+
+	if err != nil {
+		return "", err
+	}
+
+	v.cache[name] = s
+	return s, nil
+}
+
+// resolveEnv returns an environment variable value.
+func resolveEnv(name string) (string, error) {
+	s, ok := os.LookupEnv(name)
+	if !ok {
+		return "", errors.Errorf("environment variable %q is not defined", name)
+	}
+
+	return s, nil
+}
+
+// resolveGit returns git information for the working directory.
+func resolveGit(name string) (string, error) {
+	if name != "commit" {
+		return "", errors.Errorf("unknown git variable %q", name)
+	}
+
+	c, err := git.GetCommit(".", "HEAD")
+	if err != nil {
+		return "", errors.Wrap(err, "fetching git commit")
+	}
+
+	return util.StripLerna(c.Describe()), nil
+}
+
+// resolveSSM returns a decrypted parameter value from the first
+// configured region, using the credentials of the target stage,
+// or the role of the deployed function.
+func (v *variables) resolveSSM(name string) (string, error) {
+	var region string
+	if r := v.config.ConfiguredRegions(); len(r) > 0 && !strings.Contains(r[0], "*") {
+		region = r[0]
+	}
+
+	creds := v.creds
+	if v.deployed {
+		creds = Credentials{}
+	}
+
+	s := creds.Session(region)
+
+	res, err := ssm.New(s).GetParameter(&ssm.GetParameterInput{
+		Name:           &name,
+		WithDecryption: aws.Bool(true),
+	})
+
+	if err != nil {
+		return "", errors.Wrapf(err, "fetching ssm parameter %q", name)
+	}
+
+	return *res.Parameter.Value, nil
+}
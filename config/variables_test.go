@@ -0,0 +1,185 @@
+package config
+
+import (
+	"os"
+	"testing"
+
+	"github.com/tj/assert"
+)
+
+func TestConfig_variables(t *testing.T) {
+	os.Setenv("UP_TEST_DATABASE", "postgres://localhost/app")
+	defer os.Unsetenv("UP_TEST_DATABASE")
+
+	os.Setenv("UP_TEST_staging", "nested")
+	defer os.Unsetenv("UP_TEST_staging")
+
+	t.Run("resolved", func(t *testing.T) {
+		c, err := ParseConfigString(`{
+			"name": "app",
+			"regions": ["us-west-2"],
+			"environment": {
+				"DATABASE_URL": "${env:UP_TEST_DATABASE}",
+				"STAGE": "${stage}",
+				"NESTED": "${env:UP_TEST_${stage}}"
+			},
+			"stages": {
+				"production": {
+					"domain": "${stage}.example.com"
+				},
+				"staging": {
+					"domain": "${stage}.example.com",
+					"environment": {
+						"DATABASE_URL": "${env:UP_TEST_DATABASE}?stage=${stage}"
+					}
+				}
+			}
+		}`)
+
+		assert.NoError(t, err, "parse")
+		assert.Equal(t, "postgres://localhost/app", c.Environment["DATABASE_URL"])
+		assert.Equal(t, "${stage}", c.Environment["STAGE"])
+		assert.Equal(t, "${env:UP_TEST_${stage}}", c.Environment["NESTED"])
+		assert.Equal(t, "${stage}.example.com", c.Stages["production"].Domain)
+		assert.Equal(t, "${stage}.example.com", c.Stages["staging"].Domain)
+
+		assert.NoError(t, c.Override("staging"), "override")
+		assert.Equal(t, "postgres://localhost/app?stage=staging", c.Environment["DATABASE_URL"])
+		assert.Equal(t, "staging", c.Environment["STAGE"])
+		assert.Equal(t, "nested", c.Environment["NESTED"])
+		assert.Equal(t, "staging.example.com", c.Stages["staging"].Domain)
+		assert.Equal(t, "${stage}.example.com", c.Stages["production"].Domain)
+	})
+
+	t.Run("parameters", func(t *testing.T) {
+		c, err := ParseConfigString(`{
+			"name": "app",
+			"regions": ["us-west-2"],
+			"environment": {
+				"SECRET": "${ssm:/app/secret}"
+			}
+		}`)
+
+		assert.NoError(t, err, "parse")
+		assert.Equal(t, "${ssm:/app/secret}", c.Environment["SECRET"])
+		assert.Empty(t, c.Variables())
+	})
+
+	t.Run("undefined stage environment variable", func(t *testing.T) {
+		s := `{
+			"name": "app",
+			"regions": ["us-west-2"],
+			"stages": {
+				"production": {
+					"environment": {
+						"DB": "${env:UP_TEST_MISSING}"
+					}
+				}
+			}
+		}`
+
+		c, err := ParseConfigString(s)
+		assert.NoError(t, err, "parse")
+		assert.NoError(t, c.Override("staging"), "override")
+
+		c, err = ParseConfigString(s)
+		assert.NoError(t, err, "parse")
+		assert.EqualError(t, c.Override("production"), `.stages.production.environment.DB: environment variable "UP_TEST_MISSING" is not defined`)
+	})
+
+	t.Run("undefined environment variable", func(t *testing.T) {
+		_, err := ParseConfigString(`{
+			"name": "app",
+			"regions": ["us-west-2"],
+			"lambda": {
+				"role": "${env:UP_TEST_MISSING}"
+			}
+		}`)
+
+		assert.EqualError(t, err, `validating: .lambda.role: environment variable "UP_TEST_MISSING" is not defined`)
+	})
+
+	t.Run("unknown git variable", func(t *testing.T) {
+		c, err := ParseConfigString(`{
+			"name": "app",
+			"regions": ["us-west-2"],
+			"stages": {
+				"production": {
+					"domain": "${git:nope}.example.com"
+				}
+			}
+		}`)
+
+		assert.NoError(t, err, "parse")
+		assert.EqualError(t, c.Override("production"), `.stages.production.domain: unknown git variable "nope"`)
+	})
+
+	t.Run("shell variables", func(t *testing.T) {
+		c, err := ParseConfigString(`{
+			"name": "app",
+			"regions": ["us-west-2"],
+			"proxy": {
+				"command": "./server --port ${PORT} --env ${env:UP_TEST_DATABASE}"
+			}
+		}`)
+
+		assert.NoError(t, err, "parse")
+		assert.Equal(t, "./server --port ${PORT} --env postgres://localhost/app", c.Proxy.Command)
+	})
+
+	t.Run("resolved once", func(t *testing.T) {
+		os.Setenv("UP_TEST_SECRET", "p@${env:UP_TEST_DATABASE}${stage}")
+		defer os.Unsetenv("UP_TEST_SECRET")
+
+		c, err := ParseConfigString(`{
+			"name": "app",
+			"regions": ["us-west-2"],
+			"environment": {
+				"SECRET": "${env:UP_TEST_SECRET}"
+			},
+			"stages": {
+				"staging": {
+					"environment": {
+						"STAGE_SECRET": "${env:UP_TEST_SECRET}"
+					}
+				}
+			}
+		}`)
+
+		assert.NoError(t, err, "parse")
+		assert.Equal(t, "p@${env:UP_TEST_DATABASE}${stage}", c.Environment["SECRET"])
+
+		assert.NoError(t, c.Override("staging"), "override")
+		assert.Equal(t, "p@${env:UP_TEST_DATABASE}${stage}", c.Environment["SECRET"])
+		assert.Equal(t, "p@${env:UP_TEST_DATABASE}${stage}", c.Environment["STAGE_SECRET"])
+	})
+
+	t.Run("deployed", func(t *testing.T) {
+		s := `{
+			"name": "app",
+			"regions": ["us-west-2"],
+			"environment": {
+				"COMMIT": "${git:commit}",
+				"NESTED": "${env:UP_TEST_${stage}}"
+			}
+		}`
+
+		c, err := ParseConfigString(s)
+		assert.NoError(t, err, "parse")
+		assert.NoError(t, c.Override("staging"), "override")
+
+		vars := c.Variables()
+		assert.Equal(t, "nested", vars["env:UP_TEST_staging"])
+
+		os.Unsetenv("UP_TEST_staging")
+		defer os.Setenv("UP_TEST_staging", "nested")
+
+		d, err := ParseDeployedConfig([]byte(s), vars)
+		assert.NoError(t, err, "parse")
+		assert.NoError(t, d.Override("staging"), "override")
+		assert.Equal(t, c.Environment, d.Environment)
+
+		d, err = ParseDeployedConfig([]byte(s), nil)
+		assert.EqualError(t, err, `validating: .environment.COMMIT: variable "${git:commit}" was not resolved when deploying`)
+	})
+}
@@ -1,6 +1,7 @@
 module github.com/apex/up
 
 require (
+	github.com/BurntSushi/toml v0.3.1
 	github.com/NYTimes/gziphandler v0.0.0-20170916004738-97ae7fbaf816
 	github.com/alecthomas/assert v0.0.0-20170929043011-405dbfeb8e38 // indirect
 	github.com/alecthomas/colour v0.0.0-20160524082231-60882d9e2721 // indirect
@@ -11,7 +12,7 @@ require (
 	github.com/apex/log v1.3.0
 	github.com/armon/go-radix v1.0.0 // indirect
 	github.com/atotto/clipboard v0.1.2 // indirect
-	github.com/aws/aws-sdk-go v1.31.9
+	github.com/aws/aws-sdk-go v1.55.8
 	github.com/aybabtme/rgbterm v0.0.0-20170906152045-cc83f3b3ce59
 	github.com/bmizerany/assert v0.0.0-20160611221934-b7ed37b82869 // indirect
 	github.com/bradfitz/iter v0.0.0-20140124041915-454541ec3da2 // indirect
@@ -67,7 +68,7 @@ require (
 	github.com/xtgo/uuid v0.0.0-20140804021211-a0b114877d4c // indirect
 	golang.org/x/net v0.0.0-20200202094626-16171245cfb2
 	golang.org/x/sync v0.0.0-20180314180146-1d60e4601c6f // indirect
-	gopkg.in/yaml.v3 v3.0.0-20200602174320-3e3e88ca92fa // indirect
+	gopkg.in/yaml.v3 v3.0.0-20200602174320-3e3e88ca92fa
 )
 
 go 1.13
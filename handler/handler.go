@@ -25,7 +25,7 @@ import (
 // FromConfig returns the handler based on user config.
 func FromConfig(c *up.Config) (http.Handler, error) {
 	switch c.Type {
-	case "server":
+	case "server", "go":
 		return relay.New(c)
 	case "static":
 		return static.New(c), nil
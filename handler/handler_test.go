@@ -1,6 +1,7 @@
 package handler
 
 import (
+	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"os"
@@ -211,3 +212,28 @@ func TestHandler_spa(t *testing.T) {
 		assert.Equal(t, "bar css\n", res.Body.String())
 	})
 }
+
+func TestHandler_stream(t *testing.T) {
+	c, err := up.ParseConfigString(`{ "name": "app", "api": { "type": "url" }, "lambda": { "runtime": "nodejs20.x" } }`)
+	assert.NoError(t, err, "parse config")
+
+	h, err := New(c, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.WriteHeader(201)
+		fmt.Fprint(w, "data: hello\n\n")
+		w.(http.Flusher).Flush()
+		fmt.Fprint(w, "data: world\n\n")
+	}))
+	assert.NoError(t, err, "New")
+
+	res := httptest.NewRecorder()
+	req := httptest.NewRequest("GET", "/", nil)
+	req.Header.Set("Accept-Encoding", "gzip")
+	h.ServeHTTP(res, req)
+
+	assert.Equal(t, 201, res.Code)
+	assert.True(t, res.Flushed, "flushed")
+	assert.Equal(t, "text/event-stream", res.Header().Get("Content-Type"))
+	assert.Equal(t, "", res.Header().Get("Content-Encoding"))
+	assert.Equal(t, "data: hello\n\ndata: world\n\n", res.Body.String())
+}
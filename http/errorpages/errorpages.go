@@ -82,6 +82,21 @@ func (r *response) Write(b []byte) (int, error) {
 	return r.ResponseWriter.Write(b)
 }
 
+// Flush implementation.
+func (r *response) Flush() {
+	if !r.header {
+		r.WriteHeader(200)
+	}
+
+	if r.ignore {
+		return
+	}
+
+	if f, ok := r.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
 // New error pages handler.
 func New(c *up.Config, next http.Handler) (http.Handler, error) {
 	// disabled
@@ -9,7 +9,13 @@ import (
 	"github.com/apex/up"
 )
 
-// New gzip handler.
+// New gzip handler. Function url responses are streamed, and
+// flushing defeats the buffering used to sniff the response,
+// so they are left uncompressed.
 func New(c *up.Config, next http.Handler) http.Handler {
+	if c.API.Type == "url" {
+		return next
+	}
+
 	return gziphandler.GzipHandler(next)
 }
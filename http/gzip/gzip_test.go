@@ -66,3 +66,20 @@ func TestGzip(t *testing.T) {
 		assert.Equal(t, body, res.Body.String())
 	})
 }
+
+func TestGzip_url(t *testing.T) {
+	c, err := up.ParseConfigString(`{ "name": "app", "api": { "type": "url" }, "lambda": { "runtime": "nodejs20.x" } }`)
+	assert.NoError(t, err, "config")
+
+	h := New(c, hello)
+
+	res := httptest.NewRecorder()
+	req := httptest.NewRequest("GET", "/", nil)
+	req.Header.Set("Accept-Encoding", "gzip")
+
+	h.ServeHTTP(res, req)
+
+	assert.Equal(t, 200, res.Code)
+	assert.Equal(t, "", res.Header().Get("Content-Encoding"))
+	assert.Equal(t, body, res.Body.String())
+}
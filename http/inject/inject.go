@@ -29,16 +29,40 @@ func (r *response) Write(b []byte) (int, error) {
 		return r.Write(b)
 	}
 
+	if r.ignore {
+		return r.ResponseWriter.Write(b)
+	}
+
 	return r.body.Write(b)
 }
 
-// WriteHeader implementation.
+// WriteHeader implementation. Responses which are
+// not injected are written through as they are.
 func (r *response) WriteHeader(code int) {
 	r.header = true
 	w := r.ResponseWriter
 	kind := w.Header().Get("Content-Type")
 	r.ignore = !strings.HasPrefix(kind, "text/html") || code >= 300
 	r.code = code
+
+	if r.ignore {
+		w.WriteHeader(code)
+	}
+}
+
+// Flush implementation.
+func (r *response) Flush() {
+	if !r.header {
+		r.WriteHeader(200)
+	}
+
+	if !r.ignore {
+		return
+	}
+
+	if f, ok := r.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
 }
 
 // end injects if necessary.
@@ -46,8 +70,6 @@ func (r *response) end() {
 	w := r.ResponseWriter
 
 	if r.ignore {
-		w.WriteHeader(r.code)
-		r.body.WriteTo(w)
 		return
 	}
 
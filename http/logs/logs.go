@@ -39,6 +39,13 @@ func (r *response) WriteHeader(code int) {
 	r.ResponseWriter.WriteHeader(code)
 }
 
+// Flush implementation.
+func (r *response) Flush() {
+	if f, ok := r.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
 // New logs handler.
 func New(c *up.Config, next http.Handler) (http.Handler, error) {
 	if c.Logs.Disable {
@@ -50,6 +50,21 @@ func (r *rewrite) Write(b []byte) (int, error) {
 	return r.ResponseWriter.Write(b)
 }
 
+// Flush implementation.
+func (r *rewrite) Flush() {
+	if !r.header {
+		r.WriteHeader(200)
+	}
+
+	if r.isNotFound {
+		return
+	}
+
+	if f, ok := r.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
 // New redirects handler.
 func New(c *up.Config, next http.Handler) (http.Handler, error) {
 	if len(c.Redirects) == 0 {
@@ -0,0 +1,183 @@
+package relay
+
+import (
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/apex/log"
+
+	"github.com/apex/up/config"
+)
+
+// state of the circuit.
+type state string
+
+// States available.
+const (
+	closed   state = "closed"
+	open     state = "open"
+	halfOpen state = "half-open"
+)
+
+// breaker is a circuit breaker which opens when the app is crash-looping,
+// restarting too many times within the window. While open, requests are
+// rejected until the cooldown has elapsed, after which the circuit is
+// half-open, allowing a single restart to probe whether the app recovered.
+type breaker struct {
+	config config.CircuitBreaker
+	ctx    log.Interface
+
+	mu       sync.Mutex
+	state    state
+	restarts []time.Time
+	opened   time.Time
+	probed   bool
+}
+
+// newBreaker returns a closed breaker, logging transitions to ctx.
+func newBreaker(c config.CircuitBreaker, ctx log.Interface) *breaker {
+	return &breaker{
+		config: c,
+		ctx:    ctx,
+		state:  closed,
+	}
+}
+
+// Allow returns true if requests are allowed, half-opening
+// the circuit when open and the cooldown has elapsed.
+func (b *breaker) Allow() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.state != open {
+		return true
+	}
+
+	if time.Since(b.opened) < b.config.CooldownDuration() {
+		return false
+	}
+
+	b.transition(halfOpen)
+	b.probed = false
+	return true
+}
+
+// Restart returns true if a restart is allowed, opening
+// the circuit when the restart threshold is reached.
+func (b *breaker) Restart() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	now := time.Now()
+
+	switch b.state {
+	case open:
+		return false
+	case halfOpen:
+		if b.probed {
+			b.open(now)
+			return false
+		}
+		b.probed = true
+		return true
+	}
+
+	// restarts within the window
+	var restarts []time.Time
+	for _, t := range b.restarts {
+		if now.Sub(t) < b.config.WindowDuration() {
+			restarts = append(restarts, t)
+		}
+	}
+	b.restarts = append(restarts, now)
+
+	if len(b.restarts) >= b.config.Restarts {
+		b.open(now)
+		return false
+	}
+
+	return true
+}
+
+// Success closes the circuit when half-open.
+func (b *breaker) Success() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.state == halfOpen {
+		b.transition(closed)
+		b.restarts = nil
+	}
+}
+
+// Failure opens the circuit when half-open.
+func (b *breaker) Failure() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.state == halfOpen {
+		b.open(time.Now())
+	}
+}
+
+// Retry returns the duration until the circuit half-opens.
+func (b *breaker) Retry() time.Duration {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.config.CooldownDuration() - time.Since(b.opened)
+}
+
+// open the circuit.
+func (b *breaker) open(now time.Time) {
+	b.opened = now
+	b.transition(open)
+}
+
+// transition to state s.
+func (b *breaker) transition(s state) {
+	b.ctx.WithFields(log.Fields{
+		"from":     b.state,
+		"to":       s,
+		"restarts": len(b.restarts),
+	}).Warn("circuit breaker")
+
+	b.state = s
+}
+
+// tail is a writer which retains the last lines written.
+type tail struct {
+	mu    sync.Mutex
+	size  int
+	lines []string
+}
+
+// Write implementation.
+func (t *tail) Write(b []byte) (int, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	for _, s := range strings.Split(strings.TrimRight(string(b), "\n"), "\n") {
+		t.lines = append(t.lines, s)
+	}
+
+	if n := len(t.lines); n > t.size {
+		t.lines = t.lines[n-t.size:]
+	}
+
+	return len(b), nil
+}
+
+// Reset the lines.
+func (t *tail) Reset() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.lines = nil
+}
+
+// String returns the lines.
+func (t *tail) String() string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return strings.Join(t.lines, "\n")
+}
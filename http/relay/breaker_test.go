@@ -0,0 +1,56 @@
+package relay
+
+import (
+	"testing"
+	"time"
+
+	"github.com/tj/assert"
+
+	"github.com/apex/up/config"
+)
+
+func TestBreaker(t *testing.T) {
+	b := newBreaker(config.CircuitBreaker{
+		Restarts: 2,
+		Window:   60,
+		Cooldown: 30,
+	}, ctx)
+
+	t.Run("closed", func(t *testing.T) {
+		assert.True(t, b.Allow())
+		assert.True(t, b.Restart())
+		assert.Equal(t, closed, b.state)
+	})
+
+	t.Run("open", func(t *testing.T) {
+		assert.False(t, b.Restart())
+		assert.Equal(t, open, b.state)
+		assert.False(t, b.Allow())
+		assert.False(t, b.Restart())
+	})
+
+	t.Run("half-open failure", func(t *testing.T) {
+		b.opened = time.Now().Add(-time.Minute)
+		assert.True(t, b.Allow())
+		assert.Equal(t, halfOpen, b.state)
+		assert.True(t, b.Restart())
+		assert.False(t, b.Restart())
+		assert.Equal(t, open, b.state)
+	})
+
+	t.Run("half-open success", func(t *testing.T) {
+		b.opened = time.Now().Add(-time.Minute)
+		assert.True(t, b.Allow())
+		assert.True(t, b.Restart())
+		b.Success()
+		assert.Equal(t, closed, b.state)
+		assert.True(t, b.Restart())
+	})
+}
+
+func TestTail(t *testing.T) {
+	l := &tail{size: 2}
+	l.Write([]byte("one\ntwo\n"))
+	l.Write([]byte("three\n"))
+	assert.Equal(t, "two\nthree", l.String())
+}
@@ -0,0 +1,382 @@
+package relay
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/binary"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/textproto"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// FastCGI record types.
+const (
+	fcgiBeginRequest uint8 = 1
+	fcgiEndRequest   uint8 = 3
+	fcgiParams       uint8 = 4
+	fcgiStdin        uint8 = 5
+	fcgiStdout       uint8 = 6
+	fcgiStderr       uint8 = 7
+)
+
+// fcgiResponder is the FastCGI responder role.
+const fcgiResponder uint16 = 1
+
+// fcgiMaxContent is the maximum content length of a record.
+const fcgiMaxContent = 65535
+
+// fastcgi is a round tripper which relays requests to a FastCGI
+// server, translating them to CGI params. Scripts are resolved
+// relative to the document root, where paths which do not refer
+// to a .php script are handled by the index.php front controller.
+type fastcgi struct {
+	// root is the absolute document root.
+	root string
+
+	// dial returns a connection to the server at addr.
+	dial func(ctx context.Context, addr string) (net.Conn, error)
+
+	// stderr is the writer for the FastCGI stderr stream.
+	stderr io.Writer
+}
+
+// newFastCGI returns a new FastCGI round tripper, dialing the socket of the app's PORT.
+func newFastCGI(root string, stderr io.Writer) (*fastcgi, error) {
+	root, err := filepath.Abs(root)
+	if err != nil {
+		return nil, errors.Wrap(err, "resolving document root")
+	}
+
+	dialer := &net.Dialer{}
+
+	return &fastcgi{
+		root:   root,
+		stderr: stderr,
+		dial: func(ctx context.Context, addr string) (net.Conn, error) {
+			_, port, err := net.SplitHostPort(addr)
+			if err != nil {
+				return nil, err
+			}
+
+			return dialer.DialContext(ctx, "unix", socketPath(port))
+		},
+	}, nil
+}
+
+// CloseIdleConnections implementation. Connections are not kept alive.
+func (f *fastcgi) CloseIdleConnections() {}
+
+// RoundTrip implementation.
+func (f *fastcgi) RoundTrip(r *http.Request) (*http.Response, error) {
+	ctx := r.Context()
+
+	body, err := requestBody(r)
+	if err != nil {
+		return nil, errors.Wrap(err, "reading body")
+	}
+
+	conn, err := f.dial(ctx, r.URL.Host)
+	if err != nil {
+		return nil, err
+	}
+
+	if d, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(d)
+	}
+
+	// close the connection when canceled
+	stop := make(chan struct{})
+	go func() {
+		select {
+		case <-ctx.Done():
+			conn.Close()
+		case <-stop:
+		}
+	}()
+
+	closer := func() error {
+		close(stop)
+		return conn.Close()
+	}
+
+	res, err := f.roundTrip(conn, r, body)
+	if err != nil {
+		closer()
+		return nil, err
+	}
+
+	stdout := res.Body
+	res.Body = &readCloser{Reader: stdout, close: func() error {
+		stdout.Close()
+		return closer()
+	}}
+
+	return res, nil
+}
+
+// roundTrip writes the request to conn and reads the response headers.
+func (f *fastcgi) roundTrip(conn net.Conn, r *http.Request, body []byte) (*http.Response, error) {
+	w := bufio.NewWriter(conn)
+
+	begin := []byte{0, 0, 0, 0, 0, 0, 0, 0}
+	binary.BigEndian.PutUint16(begin, fcgiResponder)
+
+	if err := writeRecord(w, fcgiBeginRequest, begin); err != nil {
+		return nil, err
+	}
+
+	if err := writeStream(w, fcgiParams, encodeParams(f.params(r, len(body)))); err != nil {
+		return nil, err
+	}
+
+	if err := writeStream(w, fcgiStdin, body); err != nil {
+		return nil, err
+	}
+
+	if err := w.Flush(); err != nil {
+		return nil, err
+	}
+
+	// stream stdout until the end of the request
+	pr, pw := io.Pipe()
+	go func() {
+		pw.CloseWithError(f.readStreams(conn, pw))
+	}()
+
+	br := bufio.NewReader(pr)
+	header, err := textproto.NewReader(br).ReadMIMEHeader()
+	if err != nil {
+		pr.Close()
+		return nil, err
+	}
+
+	res := &http.Response{
+		StatusCode:    http.StatusOK,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        http.Header(header),
+		Body:          &readCloser{Reader: br, close: pr.Close},
+		ContentLength: -1,
+		Request:       r,
+	}
+
+	if s := header.Get("Status"); s != "" {
+		code, err := strconv.Atoi(strings.SplitN(s, " ", 2)[0])
+		if err != nil {
+			pr.Close()
+			return nil, errors.Wrapf(err, "parsing status %q", s)
+		}
+		res.StatusCode = code
+		res.Header.Del("Status")
+	} else if header.Get("Location") != "" {
+		res.StatusCode = http.StatusFound
+	}
+
+	res.Status = strconv.Itoa(res.StatusCode) + " " + http.StatusText(res.StatusCode)
+
+	if s := header.Get("Content-Length"); s != "" {
+		if n, err := strconv.ParseInt(s, 10, 64); err == nil {
+			res.ContentLength = n
+		}
+	}
+
+	return res, nil
+}
+
+// readStreams reads records from conn, writing stdout to w, until the end of the request.
+func (f *fastcgi) readStreams(conn net.Conn, w io.Writer) error {
+	r := bufio.NewReader(conn)
+	header := make([]byte, 8)
+
+	for {
+		if _, err := io.ReadFull(r, header); err != nil {
+			if err == io.EOF {
+				return io.ErrUnexpectedEOF
+			}
+			return err
+		}
+
+		kind := header[1]
+		n := int(binary.BigEndian.Uint16(header[4:6]))
+		padding := int(header[6])
+
+		content := make([]byte, n+padding)
+		if _, err := io.ReadFull(r, content); err != nil {
+			return err
+		}
+		content = content[:n]
+
+		switch kind {
+		case fcgiStdout:
+			if _, err := w.Write(content); err != nil {
+				return err
+			}
+		case fcgiStderr:
+			f.stderr.Write(content)
+		case fcgiEndRequest:
+			return io.EOF
+		}
+	}
+}
+
+// params returns the CGI params of the request.
+func (f *fastcgi) params(r *http.Request, length int) map[string]string {
+	script, info := splitScript(r.URL.Path)
+
+	host, port, err := net.SplitHostPort(r.Host)
+	if err != nil {
+		host = r.Host
+		port = "80"
+		if r.Header.Get("X-Forwarded-Proto") == "https" {
+			port = "443"
+		}
+	}
+
+	remoteAddr, remotePort, _ := net.SplitHostPort(r.RemoteAddr)
+
+	p := map[string]string{
+		"GATEWAY_INTERFACE": "CGI/1.1",
+		"SERVER_SOFTWARE":   "up",
+		"SERVER_PROTOCOL":   "HTTP/1.1",
+		"SERVER_NAME":       host,
+		"SERVER_PORT":       port,
+		"REQUEST_METHOD":    r.Method,
+		"REQUEST_URI":       r.URL.RequestURI(),
+		"QUERY_STRING":      r.URL.RawQuery,
+		"DOCUMENT_ROOT":     f.root,
+		"DOCUMENT_URI":      r.URL.Path,
+		"SCRIPT_NAME":       script,
+		"SCRIPT_FILENAME":   filepath.Join(f.root, filepath.FromSlash(script)),
+		"PATH_INFO":         info,
+		"REMOTE_ADDR":       remoteAddr,
+		"REMOTE_PORT":       remotePort,
+		"CONTENT_TYPE":      r.Header.Get("Content-Type"),
+		"CONTENT_LENGTH":    strconv.Itoa(length),
+		"HTTP_HOST":         r.Host,
+	}
+
+	if r.Header.Get("X-Forwarded-Proto") == "https" {
+		p["HTTPS"] = "on"
+	}
+
+	for name, values := range r.Header {
+		switch name {
+		case "Content-Type", "Content-Length":
+			continue
+		case "Proxy":
+			// prevent HTTP_PROXY from being set, see https://httpoxy.org
+			continue
+		}
+
+		p["HTTP_"+strings.ToUpper(strings.Replace(name, "-", "_", -1))] = strings.Join(values, ", ")
+	}
+
+	return p
+}
+
+// splitScript returns the script name and path info of a request path.
+func splitScript(s string) (script, info string) {
+	s = path.Clean("/" + s)
+
+	for i := 0; ; {
+		n := strings.Index(s[i:], ".php")
+		if n == -1 {
+			return "/index.php", s
+		}
+
+		end := i + n + len(".php")
+		if end == len(s) || s[end] == '/' {
+			return s[:end], s[end:]
+		}
+
+		i = end
+	}
+}
+
+// requestBody returns the request body, which is buffered as
+// FastCGI servers such as PHP-FPM require the content length.
+func requestBody(r *http.Request) ([]byte, error) {
+	if r.Body == nil || r.Body == http.NoBody {
+		return nil, nil
+	}
+
+	defer r.Body.Close()
+	return ioutil.ReadAll(r.Body)
+}
+
+// writeRecord writes a record of the given type.
+func writeRecord(w io.Writer, kind uint8, content []byte) error {
+	header := []byte{1, kind, 0, 1, 0, 0, 0, 0}
+	binary.BigEndian.PutUint16(header[4:6], uint16(len(content)))
+
+	if _, err := w.Write(header); err != nil {
+		return err
+	}
+
+	_, err := w.Write(content)
+	return err
+}
+
+// writeStream writes a stream of records of the given type, terminated by an empty record.
+func writeStream(w io.Writer, kind uint8, content []byte) error {
+	for len(content) > 0 {
+		n := len(content)
+		if n > fcgiMaxContent {
+			n = fcgiMaxContent
+		}
+
+		if err := writeRecord(w, kind, content[:n]); err != nil {
+			return err
+		}
+
+		content = content[n:]
+	}
+
+	return writeRecord(w, kind, nil)
+}
+
+// encodeParams returns the name-value pairs encoding of params.
+func encodeParams(params map[string]string) []byte {
+	var buf bytes.Buffer
+
+	size := func(n int) {
+		if n < 128 {
+			buf.WriteByte(byte(n))
+			return
+		}
+
+		b := make([]byte, 4)
+		binary.BigEndian.PutUint32(b, uint32(n)|1<<31)
+		buf.Write(b)
+	}
+
+	for name, value := range params {
+		size(len(name))
+		size(len(value))
+		buf.WriteString(name)
+		buf.WriteString(value)
+	}
+
+	return buf.Bytes()
+}
+
+// readCloser is a reader with a close func.
+type readCloser struct {
+	io.Reader
+	close func() error
+}
+
+// Close implementation.
+func (r *readCloser) Close() error {
+	return r.close()
+}
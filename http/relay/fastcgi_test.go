@@ -0,0 +1,120 @@
+package relay
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/fcgi"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/tj/assert"
+)
+
+func TestSplitScript(t *testing.T) {
+	cases := []struct {
+		path, script, info string
+	}{
+		{"/", "/index.php", "/"},
+		{"/users/tobi", "/index.php", "/users/tobi"},
+		{"/info.php", "/info.php", ""},
+		{"/admin/index.php/users/tobi", "/admin/index.php", "/users/tobi"},
+		{"/file.phpx/foo", "/index.php", "/file.phpx/foo"},
+		{"/file.phpx/bar.php", "/file.phpx/bar.php", ""},
+		{"/../../etc/passwd.php", "/etc/passwd.php", ""},
+	}
+
+	for _, c := range cases {
+		t.Run(c.path, func(t *testing.T) {
+			script, info := splitScript(c.path)
+			assert.Equal(t, c.script, script, "script")
+			assert.Equal(t, c.info, info, "info")
+		})
+	}
+}
+
+func TestFastCGI(t *testing.T) {
+	dir, err := ioutil.TempDir("", "up-fastcgi")
+	assert.NoError(t, err, "tempdir")
+	defer os.RemoveAll(dir)
+
+	l, err := net.Listen("unix", filepath.Join(dir, "fcgi.sock"))
+	assert.NoError(t, err, "listen")
+	defer l.Close()
+
+	go fcgi.Serve(l, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		env := fcgi.ProcessEnv(r)
+
+		switch r.URL.Path {
+		case "/missing":
+			w.Header().Set("X-Missing", "yes")
+			w.WriteHeader(404)
+			fmt.Fprint(w, "Not Found")
+		default:
+			b, _ := ioutil.ReadAll(r.Body)
+			fmt.Fprintf(w, "%s %s\n", r.Method, r.URL.RequestURI())
+			fmt.Fprintf(w, "script=%s root=%s\n", env["SCRIPT_FILENAME"], env["DOCUMENT_ROOT"])
+			fmt.Fprintf(w, "user-agent=%s\n", r.Header.Get("User-Agent"))
+			fmt.Fprintf(w, "proxy=%s\n", r.Header.Get("Proxy"))
+			fmt.Fprintf(w, "body=%s", b)
+		}
+	}))
+
+	stderr := new(strings.Builder)
+	f, err := newFastCGI("/var/www", stderr)
+	assert.NoError(t, err, "new")
+	f.dial = func(ctx context.Context, addr string) (net.Conn, error) {
+		var d net.Dialer
+		return d.DialContext(ctx, "unix", l.Addr().String())
+	}
+
+	t.Run("request", func(t *testing.T) {
+		req := httptest.NewRequest("POST", "/admin/index.php/users/tobi?page=2", strings.NewReader("Hello"))
+		req.Header.Set("User-Agent", "tobi")
+		req.Header.Set("Proxy", "evil")
+
+		res, err := f.RoundTrip(req)
+		assert.NoError(t, err, "round trip")
+		defer res.Body.Close()
+
+		b, err := ioutil.ReadAll(res.Body)
+		assert.NoError(t, err, "read")
+
+		assert.Equal(t, 200, res.StatusCode)
+		assertString(t, `POST /admin/index.php/users/tobi?page=2
+script=/var/www/admin/index.php root=/var/www
+user-agent=tobi
+proxy=
+body=Hello`, string(b))
+	})
+
+	t.Run("status", func(t *testing.T) {
+		res, err := f.RoundTrip(httptest.NewRequest("GET", "/missing", nil))
+		assert.NoError(t, err, "round trip")
+		defer res.Body.Close()
+
+		b, err := ioutil.ReadAll(res.Body)
+		assert.NoError(t, err, "read")
+
+		assert.Equal(t, 404, res.StatusCode)
+		assert.Equal(t, "yes", res.Header.Get("X-Missing"))
+		assert.Equal(t, "", res.Header.Get("Status"))
+		assert.Equal(t, "Not Found", string(b))
+	})
+
+	t.Run("unavailable", func(t *testing.T) {
+		f := *f
+		f.dial = func(ctx context.Context, addr string) (net.Conn, error) {
+			var d net.Dialer
+			return d.DialContext(ctx, "unix", filepath.Join(dir, "missing.sock"))
+		}
+
+		_, err := f.RoundTrip(httptest.NewRequest("GET", "/", nil))
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,36 @@
+//go:build !windows
+// +build !windows
+
+package relay
+
+import (
+	"os/exec"
+	"syscall"
+)
+
+// signals is a map of supported stop signals.
+var signals = map[string]syscall.Signal{
+	"SIGTERM": syscall.SIGTERM,
+	"SIGINT":  syscall.SIGINT,
+	"SIGQUIT": syscall.SIGQUIT,
+	"SIGHUP":  syscall.SIGHUP,
+	"SIGUSR1": syscall.SIGUSR1,
+	"SIGUSR2": syscall.SIGUSR2,
+	"SIGKILL": syscall.SIGKILL,
+}
+
+// setpgid starts the command in its own process group, so that
+// the app and any processes it spawns may be signalled together.
+func setpgid(cmd *exec.Cmd) {
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+}
+
+// signalGroup sends the named signal to the command's process group.
+func signalGroup(cmd *exec.Cmd, name string) error {
+	sig, ok := signals[name]
+	if !ok {
+		sig = syscall.SIGTERM
+	}
+
+	return syscall.Kill(-cmd.Process.Pid, sig)
+}
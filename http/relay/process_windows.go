@@ -0,0 +1,16 @@
+//go:build windows
+// +build windows
+
+package relay
+
+import (
+	"os/exec"
+)
+
+// setpgid is a no-op, as process groups are not supported.
+func setpgid(cmd *exec.Cmd) {}
+
+// signalGroup kills the command, as signals are not supported.
+func signalGroup(cmd *exec.Cmd, name string) error {
+	return cmd.Process.Kill()
+}
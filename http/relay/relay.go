@@ -4,64 +4,63 @@
 package relay
 
 import (
+	"context"
 	"fmt"
+	"io"
 	"net"
 	"net/http"
 	"net/http/httputil"
 	"net/url"
 	"os"
-	"os/exec"
+	"path/filepath"
 	"strconv"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/apex/log"
-	"github.com/facebookgo/freeport"
+	"github.com/golang/sync/errgroup"
 	"github.com/pkg/errors"
+	"github.com/tj/backoff"
 
 	"github.com/apex/up"
 	"github.com/apex/up/internal/logs"
 	"github.com/apex/up/internal/logs/writer"
-	"github.com/apex/up/internal/util"
 )
 
 // log context.
 var ctx = logs.Plugin("relay")
 
+// errCircuitOpen is returned when restarting while the circuit is open.
+var errCircuitOpen = errors.New("circuit open")
+
 // Proxy is a reverse proxy and sub-process monitor
-// for ensuring your web server is running.
+// for ensuring your web server is running. Requests
+// are balanced across the workers by least outstanding
+// requests.
 type Proxy struct {
 	config *up.Config
 
-	// transport used for the reverse proxy.
-	transport http.RoundTripper
-
-	// stdout is the log writer for structured logging output.
-	stdout *writer.Writer
+	// transport used for the reverse proxy, pooling connections to the app.
+	transport transport
 
-	// stderr is the log writer for structured logging output.
-	stderr *writer.Writer
+	// timeout is the default timeout of each request.
+	timeout time.Duration
 
-	mu sync.Mutex
+	// sidecars are the companion processes of the app.
+	sidecars []*sidecar
 
-	// restarts is the restart count.
-	restarts int
+	// workers are the instances of the app.
+	workers []*worker
 
-	// url is the active application url.
-	url *url.URL
+	// next is the worker offset used to spread requests when tied.
+	next uint32
 
 	// ReverseProxy is the reverse proxy making the requests to the app.
 	*httputil.ReverseProxy
-
-	// cmd is the current child process of the app.
-	cmd *exec.Cmd
 }
 
 // New proxy.
-//
-// We want to buffer the cleanup channel so that we can bound the
-// number of concurrent processes executing, and prevent exhausting
-// the ulimits of the host OS.
 func New(c *up.Config) (http.Handler, error) {
 	stdout, err := log.ParseLevel(c.Logs.Stdout)
 	if err != nil {
@@ -73,156 +72,418 @@ func New(c *up.Config) (http.Handler, error) {
 		return nil, errors.Wrap(err, "invalid stderr log level")
 	}
 
-	timeout := time.Duration(c.Proxy.Timeout) * time.Second
-
 	p := &Proxy{
 		config:    c,
-		stdout:    writer.New(stdout, ctx),
-		stderr:    writer.New(stderr, ctx),
-		transport: newTransport(timeout),
+		transport: newTransport(c.Proxy.Socket),
+		timeout:   time.Duration(c.Proxy.Timeout) * time.Second,
+	}
+
+	if c.Proxy.Protocol == "fastcgi" {
+		t, err := newFastCGI(c.Proxy.DocumentRoot, writer.New(stderr, ctx))
+		if err != nil {
+			return nil, err
+		}
+		p.transport = t
+	}
+
+	// the host is set per-request to the selected worker
+	p.ReverseProxy = httputil.NewSingleHostReverseProxy(&url.URL{Scheme: "http"})
+	p.ReverseProxy.Transport = p
+
+	for _, v := range c.Proxy.Sidecars {
+		p.sidecars = append(p.sidecars, newSidecar(v, c, stdout, stderr))
+	}
+
+	ports := new(ports)
+
+	for i := 0; i < c.Proxy.Workers; i++ {
+		p.workers = append(p.workers, newWorker(i+1, c, ports, p.transport, stdout, stderr))
 	}
 
 	if err := p.Start(); err != nil {
+		p.Close()
 		return nil, err
 	}
 
+	if c.Proxy.HealthCheck.Enabled() {
+		for _, w := range p.workers {
+			go w.monitor()
+		}
+	}
+
 	return p, nil
 }
 
-// Start the server.
+// Start the sidecars in order, followed by the workers.
 func (p *Proxy) Start() error {
-	if err := p.startServer(); err != nil {
-		return err
+	for _, s := range p.sidecars {
+		if err := s.Start(); err != nil {
+			return errors.Wrapf(err, "sidecar %s", s.config.Name)
+		}
 	}
 
-	p.ReverseProxy = httputil.NewSingleHostReverseProxy(p.url)
-	p.ReverseProxy.Transport = p
+	var g errgroup.Group
 
-	start := time.Now()
-	timeout := time.Duration(p.config.Proxy.ListenTimeout) * time.Second
-	ctx.Info("waiting for app to listen on PORT")
-
-	if err := util.WaitForListen(p.url, timeout); err != nil {
-		return errors.Wrapf(err, "waiting for %s to be in listening state", p.url.String())
+	for _, w := range p.workers {
+		w := w
+		g.Go(func() error {
+			return errors.Wrapf(w.Start(), "worker %d", w.id)
+		})
 	}
 
-	ctx.WithField("duration", util.MillisecondsSince(start)).Info("app listening")
-	return nil
+	return g.Wait()
 }
 
-// Restart the server.
+// Restart the workers, unless their circuit is open.
 func (p *Proxy) Restart() error {
-	p.mu.Lock()
-	defer p.mu.Unlock()
+	var g errgroup.Group
 
-	ctx.Warn("restarting")
-	p.restarts++
+	for _, w := range p.workers {
+		w := w
+		g.Go(func() error {
+			return errors.Wrapf(w.Restart(), "worker %d", w.id)
+		})
+	}
 
-	if p.cmd != nil {
-		if err := p.cmd.Process.Kill(); err != nil {
-			ctx.WithError(err).Error("killing application process")
-		}
+	return g.Wait()
+}
+
+// Close stops the workers gracefully, draining in-flight
+// requests, followed by the sidecars in reverse order.
+func (p *Proxy) Close() error {
+	var g errgroup.Group
+
+	for _, w := range p.workers {
+		g.Go(w.Close)
 	}
 
-	if err := p.Start(); err != nil {
-		return err
+	err := g.Wait()
+
+	for i := len(p.sidecars) - 1; i >= 0; i-- {
+		p.sidecars[i].Close()
 	}
 
-	ctx.WithField("restarts", p.restarts).Warn("restarted")
-	return nil
+	return err
 }
 
 // RoundTrip implementation.
+//
+// The response header of each attempt is bound by the request timeout,
+// which may be specified with the X-Up-Timeout header, and each attempt
+// by the deadline of the invocation, which is passed to the app as the
+// X-Up-Deadline header in milliseconds since the epoch.
 func (p *Proxy) RoundTrip(r *http.Request) (*http.Response, error) {
 	id := r.Header.Get("X-Request-Id")
-	ctx = ctx.WithField("id", id)
-	transport := p.transport
+	ctx := ctx.WithField("id", id)
+	timeout := p.timeout
+	deadline := p.deadline(r)
+	b := p.config.Proxy.Backoff.Backoff()
 
 	// timeout header
 	if s := r.Header.Get("X-Up-Timeout"); s != "" {
 		if n, err := strconv.ParseInt(s, 10, 64); err == nil {
-			transport = newTransport(time.Duration(n) * time.Second)
+			timeout = time.Duration(n) * time.Second
 		}
 	}
 
-	res, err := transport.RoundTrip(r)
+	// deadline header
+	r.Header.Set("X-Up-Deadline", strconv.FormatInt(deadline.UnixNano()/int64(time.Millisecond), 10))
 
-	// timeout error
-	if e, ok := err.(net.Error); ok && e.Timeout() {
-		ctx.WithError(err).Warn("request timeout")
-		return res, err
-	}
+	for {
+		w := p.pick()
+
+		// crash-looping
+		if w == nil {
+			return p.unavailable(r), nil
+		}
+
+		ctx := ctx.WithField("worker", w.id)
+		target, inflight := w.active()
+		r.URL.Host = target.Host
+		inflight.Add()
+
+		// the timeout bounds the time to the response header,
+		// so that streamed response bodies are not cut off
+		c, cancel := context.WithCancel(r.Context())
+		timer := time.AfterFunc(timeout, cancel)
+		res, err := p.transport.RoundTrip(r.WithContext(c))
+		timedOut := !timer.Stop()
+
+		// in-flight until the response body is closed
+		if err == nil {
+			res.Body = &body{ReadCloser: res.Body, done: func() {
+				cancel()
+				inflight.Done()
+			}}
+		} else {
+			cancel()
+			inflight.Done()
+		}
+
+		// retried response
+		if n := b.Attempt(); err == nil && n > 0 {
+			ctx.WithField("retries", n).Info("request retried")
+			res.Header.Set("X-Up-Retries", strconv.Itoa(n))
+		}
+
+		// success
+		if err == nil {
+			w.breaker.Success()
+			return res, nil
+		}
+
+		// timeout error
+		if e, ok := err.(net.Error); timedOut || (ok && e.Timeout()) {
+			ctx.WithError(err).Warn("request timeout")
+			return res, err
+		}
+
+		// canceled by the client
+		if r.Context().Err() != nil {
+			ctx.WithError(err).Warn("request canceled")
+			return res, err
+		}
 
-	// temporary error
-	if e, ok := err.(net.Error); ok && e.Temporary() {
-		ctx.WithError(err).Warn("request temporary error")
-		return res, err
+		if e, ok := err.(net.Error); ok && e.Temporary() {
+			// temporary error
+			ctx.WithError(err).Warn("request temporary error")
+		} else {
+			// network error, restarting unless another request already has
+			ctx.WithError(err).Error("request network error")
+			if w.target().Host == target.Host {
+				err := w.Restart()
+
+				if err == errCircuitOpen && p.pick() == nil {
+					return p.unavailable(r), nil
+				}
+
+				if err != nil {
+					ctx.WithError(err).Error("restarting")
+				}
+			}
+		}
+
+		// retry
+		if !p.retry(ctx, r, b, deadline) {
+			return res, err
+		}
 	}
+}
 
-	// network error
-	if err != nil {
-		ctx.WithError(err).Error("request network error")
-		if err := p.Restart(); err != nil {
-			ctx.WithError(err).Error("restarting")
+// pick returns the worker with the least outstanding requests, of those
+// with a closed circuit, or nil when every circuit is open. Ties are
+// spread across the workers by rotating the starting offset.
+func (p *Proxy) pick() *worker {
+	var min *worker
+	var outstanding int
+
+	offset := int(atomic.AddUint32(&p.next, 1))
+
+	for i := range p.workers {
+		w := p.workers[(offset+i)%len(p.workers)]
+
+		if !w.breaker.Allow() {
+			continue
+		}
+
+		_, inflight := w.active()
+		if n := inflight.Len(); min == nil || n < outstanding {
+			min = w
+			outstanding = n
 		}
 	}
 
-	return res, err
+	return min
 }
 
-// environment returns the server env variables.
-func (p *Proxy) environment() []string {
-	return []string{
-		env("PORT", p.url.Port()),
-		env("UP_RESTARTS", p.restarts),
+// unavailable returns the response of the worker which is soonest to half-open.
+func (p *Proxy) unavailable(r *http.Request) *http.Response {
+	w := p.workers[0]
+
+	for _, v := range p.workers[1:] {
+		if v.breaker.Retry() < w.breaker.Retry() {
+			w = v
+		}
 	}
+
+	return w.unavailable(r)
 }
 
-// startServer the server on a free port.
-func (p *Proxy) startServer() error {
-	port, err := freeport.Get()
-	if err != nil {
-		return errors.Wrap(err, "getting free port")
+// retry returns true after waiting to retry the request, which must be
+// idempotent or replayable, with attempts remaining before the deadline.
+func (p *Proxy) retry(ctx log.Interface, r *http.Request, b *backoff.Backoff, deadline time.Time) bool {
+	if !retryable(r) {
+		return false
 	}
 
-	target, err := url.Parse(fmt.Sprintf("http://127.0.0.1:%d", port))
-	if err != nil {
-		return errors.Wrap(err, "parsing url")
+	n := b.Attempt()
+	if n+1 >= p.config.Proxy.Backoff.Attempts {
+		return false
+	}
+
+	d := b.Duration()
+	if time.Now().Add(d).After(deadline) {
+		ctx.WithField("retries", n).Warn("request deadline exceeded")
+		return false
+	}
+
+	ctx.WithField("retry", n+1).WithField("delay", d).Warn("retrying request")
+
+	select {
+	case <-time.After(d):
+	case <-r.Context().Done():
+		return false
+	}
+
+	if r.GetBody != nil {
+		body, err := r.GetBody()
+		if err != nil {
+			ctx.WithError(err).Error("replaying request body")
+			return false
+		}
+		r.Body = body
 	}
 
-	p.url = target
+	return true
+}
 
-	ctx.WithField("command", p.config.Proxy.Command).WithField("PORT", port).Info("starting app")
-	p.cmd = p.command(p.config.Proxy.Command, p.environment())
+// deadline returns the request's context deadline, which is the remaining
+// time of the Lambda invocation, or the function timeout when sooner or absent.
+func (p *Proxy) deadline(r *http.Request) time.Time {
+	deadline := time.Now().Add(time.Duration(p.config.Lambda.Timeout) * time.Second)
 
-	if err := p.cmd.Start(); err != nil {
-		return errors.Wrap(err, "running command")
+	if d, ok := r.Context().Deadline(); ok && d.Before(deadline) {
+		return d
 	}
 
-	ctx.Info("started app")
-	return nil
+	return deadline
 }
 
-// command returns the command for spawning a server.
-func (p *Proxy) command(s string, env []string) *exec.Cmd {
-	cmd := exec.Command("sh", "-c", s)
-	cmd.Stdout = p.stdout
-	cmd.Stderr = p.stderr
-	cmd.Env = append(os.Environ(), append(env, "PATH=node_modules/.bin:"+os.Getenv("PATH"))...)
-	return cmd
+// retryable returns true if the request is idempotent or its body may be replayed.
+func retryable(r *http.Request) bool {
+	switch r.Method {
+	case "GET", "HEAD", "OPTIONS":
+		return true
+	}
+
+	return r.Body == nil || r.Body == http.NoBody || r.GetBody != nil
 }
 
-// newTransport returns a new http.Transport with the given timeout.
-func newTransport(timeout time.Duration) *http.Transport {
+// transport is a round tripper which may pool connections to the app.
+type transport interface {
+	http.RoundTripper
+	CloseIdleConnections()
+}
+
+// newTransport returns a new http.Transport, pooling connections to the app.
+// In socket mode connections are dialed to the socket of the app's PORT.
+func newTransport(socket bool) *http.Transport {
+	dialer := &net.Dialer{
+		Timeout:   2 * time.Second,
+		KeepAlive: 30 * time.Second,
+	}
+
+	dial := dialer.DialContext
+
+	if socket {
+		dial = func(ctx context.Context, _, addr string) (net.Conn, error) {
+			_, port, err := net.SplitHostPort(addr)
+			if err != nil {
+				return nil, err
+			}
+
+			return dialer.DialContext(ctx, "unix", socketPath(port))
+		}
+	}
+
 	return &http.Transport{
-		DialContext: (&net.Dialer{
-			Timeout:   2 * time.Second,
-			KeepAlive: 2 * time.Second,
-			DualStack: true,
-		}).DialContext,
-		ResponseHeaderTimeout: timeout,
-		DisableKeepAlives:     true,
+		DialContext:         dial,
+		MaxIdleConns:        100,
+		MaxIdleConnsPerHost: 100,
+		IdleConnTimeout:     90 * time.Second,
+	}
+}
+
+// socketPath returns the Unix domain socket path for port.
+func socketPath(port string) string {
+	return filepath.Join(os.TempDir(), fmt.Sprintf("up-%s.sock", port))
+}
+
+// group is a count of in-flight requests, which unlike
+// sync.WaitGroup may be added to while being waited on.
+type group struct {
+	mu      sync.Mutex
+	n       int
+	waiters []chan struct{}
+}
+
+// Add a request.
+func (g *group) Add() {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.n++
+}
+
+// Done completes a request.
+func (g *group) Done() {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.n--
+
+	if g.n == 0 {
+		for _, c := range g.waiters {
+			close(c)
+		}
+		g.waiters = nil
+	}
+}
+
+// Len returns the number of in-flight requests.
+func (g *group) Len() int {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.n
+}
+
+// Drained returns a channel which is closed once no requests are in-flight.
+func (g *group) Drained() <-chan struct{} {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	c := make(chan struct{})
+
+	if g.n == 0 {
+		close(c)
+	} else {
+		g.waiters = append(g.waiters, c)
 	}
+
+	return c
+}
+
+// body is a response body which calls done once closed. It may be
+// written to when the response is a protocol upgrade such as WebSockets.
+type body struct {
+	io.ReadCloser
+	once sync.Once
+	done func()
+}
+
+// Write implementation.
+func (b *body) Write(p []byte) (int, error) {
+	w, ok := b.ReadCloser.(io.Writer)
+	if !ok {
+		return 0, errors.New("response body is not writable")
+	}
+
+	return w.Write(p)
+}
+
+// Close implementation.
+func (b *body) Close() error {
+	err := b.ReadCloser.Close()
+	b.once.Do(b.done)
+	return err
 }
 
 // env returns an environment variable.
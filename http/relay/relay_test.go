@@ -1,13 +1,20 @@
 package relay
 
 import (
+	"context"
+	"fmt"
+	"io/ioutil"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
 	"os"
+	"strconv"
 	"strings"
+	"sync"
 	"testing"
 	"time"
 
+	"github.com/facebookgo/freeport"
 	"github.com/tj/assert"
 
 	"github.com/apex/up"
@@ -57,7 +64,8 @@ func TestRelay(t *testing.T) {
 		newHandler(t)
 
 		res := httptest.NewRecorder()
-		req := httptest.NewRequest("GET", "/echo/01BM82CJ9K1WK6EFJX8C1R4YH7/foo%20%25%20bar%20&%20baz%20=%20raz", nil)
+		req, deadline, cancel := withDeadline(httptest.NewRequest("GET", "/echo/01BM82CJ9K1WK6EFJX8C1R4YH7/foo%20%25%20bar%20&%20baz%20=%20raz", nil))
+		defer cancel()
 		req.Header.Set("Host", "example.com")
 		req.Header.Set("User-Agent", "tobi")
 		h.ServeHTTP(res, req)
@@ -67,8 +75,8 @@ func TestRelay(t *testing.T) {
     "host": "example.com",
     "user-agent": "tobi",
     "x-forwarded-for": "192.0.2.1",
-    "accept-encoding": "gzip",
-    "connection": "close"
+    "x-up-deadline": "` + deadline + `",
+    "accept-encoding": "gzip"
   },
   "url": "/echo/01BM82CJ9K1WK6EFJX8C1R4YH7/foo%20%25%20bar%20&%20baz%20=%20raz",
   "body": ""
@@ -83,7 +91,8 @@ func TestRelay(t *testing.T) {
 		newHandler(t)
 
 		res := httptest.NewRecorder()
-		req := httptest.NewRequest("POST", "/echo/something", strings.NewReader("Some body here"))
+		req, deadline, cancel := withDeadline(httptest.NewRequest("POST", "/echo/something", strings.NewReader("Some body here")))
+		defer cancel()
 		h.ServeHTTP(res, req)
 
 		body := `{
@@ -91,8 +100,8 @@ func TestRelay(t *testing.T) {
     "host": "example.com",
     "content-length": "14",
     "x-forwarded-for": "192.0.2.1",
-    "accept-encoding": "gzip",
-    "connection": "close"
+    "x-up-deadline": "` + deadline + `",
+    "accept-encoding": "gzip"
   },
   "url": "/echo/something",
   "body": "Some body here"
@@ -126,6 +135,29 @@ func TestRelay(t *testing.T) {
 		assertString(t, "Hello World", res.Body.String())
 	})
 
+	t.Run("crash retry", func(t *testing.T) {
+		newHandler(t)
+
+		res := httptest.NewRecorder()
+		req := httptest.NewRequest("GET", "/flaky", nil)
+		h.ServeHTTP(res, req)
+
+		assert.Equal(t, 200, res.Code)
+		assert.Equal(t, "1", res.Header().Get("X-Up-Retries"))
+		assertString(t, "Hello World", res.Body.String())
+	})
+
+	t.Run("crash without replayable body", func(t *testing.T) {
+		newHandler(t)
+
+		res := httptest.NewRecorder()
+		req := httptest.NewRequest("POST", "/flaky", strings.NewReader("Some body here"))
+		h.ServeHTTP(res, req)
+
+		assert.Equal(t, 502, res.Code)
+		assert.Equal(t, "", res.Header().Get("X-Up-Retries"))
+	})
+
 	t.Run("timeout", func(t *testing.T) {
 		newHandler(t)
 
@@ -146,6 +178,18 @@ func TestRelay(t *testing.T) {
 		assertString(t, "Hello World", res.Body.String())
 	})
 
+	t.Run("timeout streaming", func(t *testing.T) {
+		newHandler(t)
+
+		res := httptest.NewRecorder()
+		req := httptest.NewRequest("GET", "/stream", nil)
+		req.Header.Set("X-Up-Timeout", "1")
+		h.ServeHTTP(res, req)
+
+		assert.Equal(t, 200, res.Code)
+		assertString(t, "Hello World", res.Body.String())
+	})
+
 	t.Run("timeout header field", func(t *testing.T) {
 		newHandler(t)
 
@@ -170,6 +214,374 @@ func TestRelay(t *testing.T) {
 	})
 }
 
+func TestRelay_socket(t *testing.T) {
+	os.Chdir("testdata/basic")
+	defer os.Chdir("../..")
+
+	c := &up.Config{
+		Proxy: config.Relay{
+			Timeout:       2,
+			ListenTimeout: 2,
+			Socket:        true,
+			HealthCheck:   config.HealthCheck{Path: "/health"},
+		},
+	}
+
+	assert.NoError(t, c.Default(), "default")
+
+	h, err := New(c)
+	assert.NoError(t, err, "init")
+	p := h.(*Proxy)
+
+	socket := socketPath(p.workers[0].url.Port())
+	_, err = os.Stat(socket)
+	assert.NoError(t, err, "stat socket")
+
+	res := httptest.NewRecorder()
+	req := httptest.NewRequest("GET", "/hello", nil)
+	h.ServeHTTP(res, req)
+
+	assert.Equal(t, 200, res.Code)
+	assertString(t, "Hello World", res.Body.String())
+
+	assert.NoError(t, p.Close(), "close")
+	_, err = os.Stat(socket)
+	assert.True(t, os.IsNotExist(err), "socket removed")
+}
+
+func TestRelay_workers(t *testing.T) {
+	os.Chdir("testdata/basic")
+	defer os.Chdir("../..")
+
+	c := &up.Config{
+		Proxy: config.Relay{
+			Timeout:       2,
+			ListenTimeout: 2,
+			Workers:       3,
+		},
+	}
+
+	assert.NoError(t, c.Default(), "default")
+
+	h, err := New(c)
+	assert.NoError(t, err, "init")
+	p := h.(*Proxy)
+	defer p.Close()
+
+	t.Run("least outstanding", func(t *testing.T) {
+		var mu sync.Mutex
+		var wg sync.WaitGroup
+		workers := map[string]bool{}
+
+		for i := 0; i < 3; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				res := httptest.NewRecorder()
+				req := httptest.NewRequest("GET", "/worker", nil)
+				h.ServeHTTP(res, req)
+				mu.Lock()
+				workers[res.Body.String()] = true
+				mu.Unlock()
+			}()
+			time.Sleep(50 * time.Millisecond)
+		}
+
+		wg.Wait()
+		assert.Equal(t, map[string]bool{"1": true, "2": true, "3": true}, workers)
+	})
+
+	t.Run("restarted independently", func(t *testing.T) {
+		crashed := p.workers[1]
+		assert.NoError(t, signalGroup(crashed.cmd, "SIGKILL"), "kill")
+		<-crashed.exited
+
+		for range p.workers {
+			res := httptest.NewRecorder()
+			req := httptest.NewRequest("GET", "/hello", nil)
+			h.ServeHTTP(res, req)
+			assert.Equal(t, 200, res.Code)
+		}
+
+		for _, w := range p.workers {
+			w.mu.Lock()
+			if w == crashed {
+				assert.Equal(t, 1, w.restarts)
+			} else {
+				assert.Equal(t, 0, w.restarts)
+			}
+			w.mu.Unlock()
+		}
+	})
+}
+
+func TestRelay_sidecars(t *testing.T) {
+	os.Chdir("testdata/basic")
+	defer os.Chdir("../..")
+
+	port, err := freeport.Get()
+	assert.NoError(t, err, "port")
+
+	c := &up.Config{
+		Proxy: config.Relay{
+			Timeout:       2,
+			ListenTimeout: 2,
+			Sidecars: config.Sidecars{
+				{
+					Name:        "hello",
+					Command:     "node sidecar.js",
+					Environment: config.Environment{"SIDECAR_PORT": strconv.Itoa(port)},
+					Port:        port,
+				},
+			},
+		},
+	}
+
+	assert.NoError(t, c.Default(), "default")
+
+	h, err := New(c)
+	assert.NoError(t, err, "init")
+	p := h.(*Proxy)
+	s := p.sidecars[0]
+
+	get := func(t *testing.T) string {
+		res, err := http.Get(fmt.Sprintf("http://127.0.0.1:%d", port))
+		assert.NoError(t, err, "get")
+		defer res.Body.Close()
+		b, err := ioutil.ReadAll(res.Body)
+		assert.NoError(t, err, "read")
+		return string(b)
+	}
+
+	t.Run("started", func(t *testing.T) {
+		assert.Equal(t, "Hello Sidecar", get(t))
+	})
+
+	t.Run("restarted on failure", func(t *testing.T) {
+		s.mu.Lock()
+		cmd, exited := s.cmd, s.exited
+		s.mu.Unlock()
+
+		assert.NoError(t, signalGroup(cmd, "SIGKILL"), "kill")
+		<-exited
+
+		u := &url.URL{Host: fmt.Sprintf("127.0.0.1:%d", port)}
+		assert.NoError(t, util.WaitForListen(u, 2*time.Second), "listen")
+		assert.Equal(t, "Hello Sidecar", get(t))
+
+		s.mu.Lock()
+		assert.Equal(t, 1, s.restarts)
+		s.mu.Unlock()
+	})
+
+	t.Run("stopped after the app", func(t *testing.T) {
+		assert.NoError(t, p.Close(), "close")
+
+		select {
+		case <-s.exited:
+		default:
+			t.Fatal("expected sidecar to have exited")
+		}
+
+		select {
+		case <-p.workers[0].exited:
+		default:
+			t.Fatal("expected app to have exited")
+		}
+	})
+}
+
+func TestRelay_healthCheck(t *testing.T) {
+	os.Chdir("testdata/basic")
+	defer os.Chdir("../..")
+
+	newConfig := func(hc config.HealthCheck) *up.Config {
+		c := &up.Config{
+			Proxy: config.Relay{
+				Timeout:       2,
+				ListenTimeout: 2,
+				HealthCheck:   hc,
+			},
+		}
+
+		assert.NoError(t, c.Default(), "default")
+		return c
+	}
+
+	t.Run("healthy", func(t *testing.T) {
+		h, err := New(newConfig(config.HealthCheck{Path: "/health"}))
+		assert.NoError(t, err, "init")
+
+		res := httptest.NewRecorder()
+		req := httptest.NewRequest("GET", "/hello", nil)
+		h.ServeHTTP(res, req)
+
+		assert.Equal(t, 200, res.Code)
+		assertString(t, "Hello World", res.Body.String())
+	})
+
+	t.Run("unhealthy", func(t *testing.T) {
+		_, err := New(newConfig(config.HealthCheck{Path: "/health", Status: 204}))
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "/health responded with 200, expected 204")
+	})
+
+	t.Run("liveness", func(t *testing.T) {
+		h, err := New(newConfig(config.HealthCheck{Path: "/health", Interval: 1, Threshold: 1}))
+		assert.NoError(t, err, "init")
+
+		res := httptest.NewRecorder()
+		req := httptest.NewRequest("GET", "/sick", nil)
+		h.ServeHTTP(res, req)
+		assert.Equal(t, 200, res.Code)
+
+		time.Sleep(1500 * time.Millisecond)
+
+		w := h.(*Proxy).workers[0]
+		w.mu.Lock()
+		assert.Equal(t, 1, w.restarts)
+		w.mu.Unlock()
+	})
+}
+
+func TestRelay_circuitBreaker(t *testing.T) {
+	os.Chdir("testdata/basic")
+	defer os.Chdir("../..")
+
+	c := &up.Config{
+		Proxy: config.Relay{
+			Timeout:       2,
+			ListenTimeout: 2,
+			CircuitBreaker: config.CircuitBreaker{
+				Restarts: 2,
+			},
+		},
+	}
+
+	assert.NoError(t, c.Default(), "default")
+
+	h, err := New(c)
+	assert.NoError(t, err, "init")
+	p := h.(*Proxy)
+
+	// crash until open
+	res := httptest.NewRecorder()
+	req := httptest.NewRequest("GET", "/throw", nil)
+	h.ServeHTTP(res, req)
+
+	assert.Equal(t, 503, res.Code)
+	assert.Equal(t, "30", res.Header().Get("Retry-After"))
+	assert.Contains(t, res.Body.String(), "App is crash-looping, retrying in 30s")
+	assert.Contains(t, res.Body.String(), "yaynode is not defined")
+
+	// open
+	start := time.Now()
+	res = httptest.NewRecorder()
+	req = httptest.NewRequest("GET", "/hello", nil)
+	h.ServeHTTP(res, req)
+
+	assert.Equal(t, 503, res.Code)
+	assert.True(t, time.Since(start) < 100*time.Millisecond)
+
+	// half-open after the cooldown
+	b := p.workers[0].breaker
+	b.mu.Lock()
+	b.opened = time.Now().Add(-time.Minute)
+	b.mu.Unlock()
+
+	res = httptest.NewRecorder()
+	req = httptest.NewRequest("GET", "/hello", nil)
+	h.ServeHTTP(res, req)
+
+	assert.Equal(t, 200, res.Code)
+	assert.Equal(t, closed, b.state)
+}
+
+func TestRelay_Close(t *testing.T) {
+	os.Chdir("testdata/basic")
+	defer os.Chdir("../..")
+
+	c := &up.Config{
+		Proxy: config.Relay{
+			Timeout:       2,
+			ListenTimeout: 2,
+		},
+	}
+
+	assert.NoError(t, c.Default(), "default")
+
+	h, err := New(c)
+	assert.NoError(t, err, "init")
+	p := h.(*Proxy)
+
+	res := httptest.NewRecorder()
+	done := make(chan struct{})
+
+	go func() {
+		req := httptest.NewRequest("GET", "/slow", nil)
+		h.ServeHTTP(res, req)
+		close(done)
+	}()
+
+	time.Sleep(100 * time.Millisecond)
+	assert.NoError(t, p.Close(), "close")
+	<-done
+
+	assert.Equal(t, 200, res.Code)
+	assertString(t, "Hello", res.Body.String())
+
+	select {
+	case <-p.workers[0].exited:
+	default:
+		t.Fatal("expected app to have exited")
+	}
+}
+
+func BenchmarkRelay(b *testing.B) {
+	os.Chdir("testdata/basic")
+	defer os.Chdir("../..")
+
+	c := &up.Config{
+		Proxy: config.Relay{
+			Timeout:       2,
+			ListenTimeout: 2,
+		},
+	}
+
+	assert.NoError(b, c.Default(), "default")
+
+	h, err := New(c)
+	assert.NoError(b, err, "init")
+	p := h.(*Proxy)
+	defer p.Close()
+
+	run := func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			res := httptest.NewRecorder()
+			req := httptest.NewRequest("GET", "/hello", nil)
+			h.ServeHTTP(res, req)
+
+			if res.Code != 200 {
+				b.Fatalf("expected 200, got %d", res.Code)
+			}
+		}
+	}
+
+	b.Run("pooled", run)
+
+	b.Run("unpooled", func(b *testing.B) {
+		p.transport = &http.Transport{DisableKeepAlives: true}
+		run(b)
+	})
+}
+
+// withDeadline returns the request with a deadline, the deadline in milliseconds, and its cancel func.
+func withDeadline(r *http.Request) (*http.Request, string, context.CancelFunc) {
+	d := time.Now().Add(10 * time.Second)
+	c, cancel := context.WithDeadline(r.Context(), d)
+	return r.WithContext(c), strconv.FormatInt(d.UnixNano()/int64(time.Millisecond), 10), cancel
+}
+
 func assertString(t testing.TB, want, got string) {
 	t.Helper()
 	if want != got {
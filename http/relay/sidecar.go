@@ -0,0 +1,241 @@
+package relay
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"os/exec"
+	"sync"
+	"time"
+
+	"github.com/apex/log"
+	"github.com/pkg/errors"
+	"github.com/tj/backoff"
+
+	"github.com/apex/up"
+	"github.com/apex/up/config"
+	"github.com/apex/up/internal/logs/writer"
+	"github.com/apex/up/internal/util"
+)
+
+// sidecar is a companion process of the app, started before the
+// workers and stopped after them, restarted by its restart policy.
+type sidecar struct {
+	config config.Sidecar
+	relay  config.Relay
+
+	// ctx is the log context tagged with the sidecar name.
+	ctx log.Interface
+
+	// stdout is the log writer for structured logging output.
+	stdout *writer.Writer
+
+	// stderr is the log writer for structured logging output.
+	stderr *writer.Writer
+
+	// backoff of restarts.
+	backoff backoff.Backoff
+
+	mu sync.Mutex
+
+	// restarts is the restart count.
+	restarts int
+
+	// started is the start time of the current process.
+	started time.Time
+
+	// cmd is the current process of the sidecar.
+	cmd *exec.Cmd
+
+	// exited is closed when the current process exits.
+	exited chan struct{}
+
+	// done is closed when the sidecar is closed.
+	done chan struct{}
+}
+
+// newSidecar returns a new sidecar.
+func newSidecar(s config.Sidecar, c *up.Config, stdout, stderr log.Level) *sidecar {
+	ctx := ctx.WithField("sidecar", s.Name)
+
+	return &sidecar{
+		config: s,
+		relay:  c.Proxy,
+		ctx:    ctx,
+		stdout: writer.New(stdout, ctx),
+		stderr: writer.New(stderr, ctx),
+		backoff: backoff.Backoff{
+			Min:    100 * time.Millisecond,
+			Max:    10 * time.Second,
+			Factor: 2,
+		},
+		done: make(chan struct{}),
+	}
+}
+
+// Start the sidecar, waiting for it to listen on its port when present.
+func (s *sidecar) Start() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.start(); err != nil {
+		return err
+	}
+
+	if s.config.Port == 0 {
+		return nil
+	}
+
+	start := time.Now()
+	timeout := time.Duration(s.relay.ListenTimeout) * time.Second
+	u := &url.URL{Scheme: "http", Host: fmt.Sprintf("127.0.0.1:%d", s.config.Port)}
+	s.ctx.WithField("port", s.config.Port).Info("waiting for sidecar to listen")
+
+	if err := util.WaitForListen(u, timeout); err != nil {
+		return errors.Wrapf(err, "waiting for %s to be in listening state", u.String())
+	}
+
+	s.ctx.WithField("duration", util.MillisecondsSince(start)).Info("sidecar listening")
+	return nil
+}
+
+// Close stops the sidecar.
+func (s *sidecar) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	select {
+	case <-s.done:
+		return nil
+	default:
+		close(s.done)
+	}
+
+	if s.cmd == nil {
+		return nil
+	}
+
+	select {
+	case <-s.exited:
+		return nil
+	default:
+	}
+
+	start := time.Now()
+	timeout := time.After(time.Duration(s.relay.StopTimeout) * time.Second)
+	s.ctx.WithField("signal", s.relay.StopSignal).Info("signalling sidecar")
+
+	if err := signalGroup(s.cmd, s.relay.StopSignal); err != nil {
+		s.ctx.WithError(err).Error("signalling sidecar")
+	}
+
+	select {
+	case <-s.exited:
+		s.ctx.WithField("duration", util.MillisecondsSince(start)).Info("sidecar stopped")
+	case <-timeout:
+		s.ctx.Warn("timed out stopping sidecar, killing")
+		if err := signalGroup(s.cmd, "SIGKILL"); err != nil {
+			s.ctx.WithError(err).Error("killing sidecar")
+		}
+		<-s.exited
+	}
+
+	return nil
+}
+
+// start the sidecar process.
+func (s *sidecar) start() error {
+	s.ctx.WithField("command", s.config.Command).Info("starting sidecar")
+
+	cmd := exec.Command("sh", "-c", s.config.Command)
+	cmd.Stdout = s.stdout
+	cmd.Stderr = s.stderr
+	cmd.Env = append(os.Environ(), s.environment()...)
+	setpgid(cmd)
+
+	if err := cmd.Start(); err != nil {
+		return errors.Wrap(err, "running command")
+	}
+
+	exited := make(chan struct{})
+	s.cmd = cmd
+	s.exited = exited
+	s.started = time.Now()
+
+	go func() {
+		err := cmd.Wait()
+		close(exited)
+		s.exit(cmd, err)
+	}()
+
+	s.ctx.Info("started sidecar")
+	return nil
+}
+
+// exit handles the exit of cmd, restarting the sidecar by its restart policy.
+func (s *sidecar) exit(cmd *exec.Cmd, err error) {
+	select {
+	case <-s.done:
+		return
+	default:
+	}
+
+	ctx := s.ctx.WithField("status", util.ExitStatus(cmd, err))
+
+	if !s.restartable(err) {
+		ctx.Warn("sidecar exited")
+		return
+	}
+
+	// reset the backoff of sidecars which were running for a while
+	if time.Since(s.started) > s.backoff.Max {
+		s.backoff.Reset()
+	}
+
+	d := s.backoff.Duration()
+	ctx.WithField("delay", d).Warn("sidecar exited, restarting")
+
+	select {
+	case <-s.done:
+		return
+	case <-time.After(d):
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	select {
+	case <-s.done:
+		return
+	default:
+	}
+
+	s.restarts++
+
+	if err := s.start(); err != nil {
+		s.ctx.WithError(err).Error("restarting sidecar")
+	}
+}
+
+// restartable returns true if the sidecar should be restarted after exiting with err.
+func (s *sidecar) restartable(err error) bool {
+	switch s.config.Restart {
+	case "always":
+		return true
+	case "on-failure":
+		return err != nil
+	default:
+		return false
+	}
+}
+
+// environment returns the sidecar env variables.
+func (s *sidecar) environment() []string {
+	var v []string
+
+	for name, val := range s.config.Environment {
+		v = append(v, env(name, val))
+	}
+
+	return append(v, env("UP_RESTARTS", s.restarts))
+}
@@ -4,6 +4,8 @@ const qs = require('querystring');
 const port = process.env.PORT;
 
 let server;
+let sick = false;
+const booted = Date.now();
 
 const routes = {};
 
@@ -27,6 +29,25 @@ routes['/timeout'] = (req, res) => {
   }, 50000);
 };
 
+routes['/stream'] = (req, res) => {
+  res.write('Hello')
+  setTimeout(function(){
+    res.end(' World')
+  }, 1500);
+};
+
+routes['/slow'] = (req, res) => {
+  setTimeout(function(){
+    res.end('Hello')
+  }, 500);
+};
+
+routes['/worker'] = (req, res) => {
+  setTimeout(function(){
+    res.end(process.env.UP_WORKER)
+  }, 300);
+};
+
 routes['/throw'] = (req, res) => {
   yaynode()
 };
@@ -35,6 +56,21 @@ routes['/exit'] = (req, res) => {
   process.exit()
 };
 
+routes['/health'] = (req, res) => {
+  res.statusCode = sick || Date.now() - booted < 300 ? 503 : 200
+  res.end()
+};
+
+routes['/sick'] = (req, res) => {
+  sick = true
+  res.end()
+};
+
+routes['/flaky'] = (req, res) => {
+  if (process.env.UP_RESTARTS == '0') process.exit()
+  res.end('Hello World')
+};
+
 server = http.createServer((req, res) => {
   const r = Object.keys(routes).find(pattern => req.url.indexOf(pattern) === 0);
   const handler = r && routes[r];
@@ -45,4 +81,4 @@ server = http.createServer((req, res) => {
 
   res.setHeader('Content-Type', 'text/plain')
   res.end('Hello World')
-}).listen(port);
+}).listen(process.env.UP_SOCKET || port);
@@ -0,0 +1,5 @@
+const http = require('http');
+
+http.createServer((req, res) => {
+  res.end('Hello Sidecar')
+}).listen(process.env.SIDECAR_PORT);
@@ -0,0 +1,459 @@
+package relay
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/apex/log"
+	"github.com/facebookgo/freeport"
+	"github.com/pkg/errors"
+	"github.com/tj/backoff"
+
+	"github.com/apex/up"
+	"github.com/apex/up/internal/logs/writer"
+	"github.com/apex/up/internal/util"
+)
+
+// worker is an instance of the app, supervised and
+// restarted independently of the other workers.
+type worker struct {
+	// id of the worker, starting at 1.
+	id int
+
+	config *up.Config
+
+	// ctx is the log context tagged with the worker id.
+	ctx log.Interface
+
+	// ports allocated to the workers.
+	ports *ports
+
+	// transport used for health checks.
+	transport transport
+
+	// stdout is the log writer for structured logging output.
+	stdout *writer.Writer
+
+	// stderr is the log writer for structured logging output.
+	stderr *writer.Writer
+
+	// lines are the last lines written to stderr by the app.
+	lines *tail
+
+	// breaker is the circuit breaker opened when the app is crash-looping.
+	breaker *breaker
+
+	mu sync.Mutex
+
+	// restarts is the restart count.
+	restarts int
+
+	// url is the active application url.
+	url *url.URL
+
+	// inflight is the group of in-flight requests to the active app.
+	inflight *group
+
+	// cmd is the current child process of the app.
+	cmd *exec.Cmd
+
+	// exited is closed when the current child process exits.
+	exited chan struct{}
+
+	// done is closed when the worker is closed.
+	done chan struct{}
+}
+
+// newWorker returns a new worker.
+func newWorker(id int, c *up.Config, p *ports, t transport, stdout, stderr log.Level) *worker {
+	ctx := ctx.WithField("worker", id)
+
+	return &worker{
+		id:        id,
+		config:    c,
+		ctx:       ctx,
+		ports:     p,
+		transport: t,
+		stdout:    writer.New(stdout, ctx),
+		stderr:    writer.New(stderr, ctx),
+		lines:     &tail{size: 10},
+		breaker:   newBreaker(c.Proxy.CircuitBreaker, ctx),
+		done:      make(chan struct{}),
+	}
+}
+
+// Start the app.
+func (w *worker) Start() error {
+	if err := w.startServer(); err != nil {
+		return err
+	}
+
+	start := time.Now()
+	timeout := time.Duration(w.config.Proxy.ListenTimeout) * time.Second
+	w.ctx.Info("waiting for app to listen on PORT")
+
+	if err := util.WaitForListen(w.listener(), timeout); err != nil {
+		return errors.Wrapf(err, "waiting for %s to be in listening state", w.listener().String())
+	}
+
+	w.ctx.WithField("duration", util.MillisecondsSince(start)).Info("app listening")
+
+	if !w.config.Proxy.HealthCheck.Enabled() {
+		return nil
+	}
+
+	w.ctx.Info("waiting for app to be healthy")
+
+	if err := w.waitForHealthy(w.url, timeout-time.Since(start)); err != nil {
+		return errors.Wrapf(err, "waiting for %s to be healthy", w.url.String())
+	}
+
+	w.ctx.WithField("duration", util.MillisecondsSince(start)).Info("app healthy")
+	return nil
+}
+
+// Restart the app, unless the circuit is open.
+func (w *worker) Restart() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	select {
+	case <-w.done:
+		return errors.New("proxy closed")
+	default:
+	}
+
+	if !w.breaker.Restart() {
+		return errCircuitOpen
+	}
+
+	w.ctx.Warn("restarting")
+	w.restarts++
+
+	if w.cmd != nil {
+		w.stop()
+		w.transport.CloseIdleConnections()
+	}
+
+	if err := w.Start(); err != nil {
+		w.breaker.Failure()
+		return err
+	}
+
+	w.ctx.WithField("restarts", w.restarts).Warn("restarted")
+	return nil
+}
+
+// Close stops the app gracefully, draining in-flight requests.
+func (w *worker) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	select {
+	case <-w.done:
+		return nil
+	default:
+		close(w.done)
+	}
+
+	w.ctx.Info("stopping")
+	w.stop()
+	return nil
+}
+
+// stop drains in-flight requests and signals the app's process group to
+// exit, killing it when the stop timeout is exceeded. The app is stopped
+// immediately when its process has already exited.
+func (w *worker) stop() {
+	select {
+	case <-w.exited:
+		return
+	default:
+	}
+
+	start := time.Now()
+	timeout := time.After(time.Duration(w.config.Proxy.StopTimeout) * time.Second)
+	select {
+	case <-w.inflight.Drained():
+		w.ctx.WithField("duration", util.MillisecondsSince(start)).Info("drained requests")
+	case <-w.exited:
+		return
+	case <-timeout:
+		w.ctx.Warn("timed out draining requests")
+	}
+
+	sig := w.config.Proxy.StopSignal
+	w.ctx.WithField("signal", sig).Info("signalling app")
+
+	if err := signalGroup(w.cmd, sig); err != nil {
+		w.ctx.WithError(err).Error("signalling app")
+	}
+
+	select {
+	case <-w.exited:
+		w.ctx.WithField("duration", util.MillisecondsSince(start)).Info("app stopped")
+	case <-timeout:
+		w.ctx.Warn("timed out stopping app, killing")
+		if err := signalGroup(w.cmd, "SIGKILL"); err != nil {
+			w.ctx.WithError(err).Error("killing app")
+		}
+		<-w.exited
+	}
+}
+
+// waitForHealthy polls the health check of u until it passes or the timeout is exceeded.
+func (w *worker) waitForHealthy(u *url.URL, timeout time.Duration) error {
+	timedout := time.After(timeout)
+
+	b := backoff.Backoff{
+		Min:    100 * time.Millisecond,
+		Max:    time.Second,
+		Factor: 1.5,
+	}
+
+	var err error
+
+	for {
+		select {
+		case <-timedout:
+			if err == nil {
+				return errors.Errorf("timed out after %s", timeout)
+			}
+			return errors.Wrapf(err, "timed out after %s", timeout)
+		case <-time.After(b.Duration()):
+			if err = w.check(u); err == nil {
+				return nil
+			}
+			w.ctx.WithError(err).Debug("health check failed")
+		}
+	}
+}
+
+// monitor performs liveness checks, restarting the app when unhealthy.
+func (w *worker) monitor() {
+	hc := w.config.Proxy.HealthCheck
+	ticker := time.NewTicker(hc.IntervalDuration())
+	defer ticker.Stop()
+
+	var failures int
+
+	for {
+		select {
+		case <-w.done:
+			return
+		case <-ticker.C:
+		}
+
+		start := time.Now()
+		err := w.check(w.target())
+
+		if err == nil {
+			w.ctx.WithField("duration", util.MillisecondsSince(start)).Debug("health check passed")
+			failures = 0
+			continue
+		}
+
+		failures++
+		w.ctx.WithError(err).WithField("failures", failures).Warn("health check failed")
+
+		if failures < hc.Threshold {
+			continue
+		}
+
+		w.ctx.WithField("failures", failures).Error("app unhealthy")
+		failures = 0
+
+		if err := w.Restart(); err != nil {
+			w.ctx.WithError(err).Error("restarting")
+		}
+	}
+}
+
+// check performs a health check request against u.
+func (w *worker) check(u *url.URL) error {
+	hc := w.config.Proxy.HealthCheck
+	client := &http.Client{
+		Transport: w.transport,
+		Timeout:   hc.TimeoutDuration(),
+	}
+
+	res, err := client.Get(u.String() + hc.Path)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	io.Copy(ioutil.Discard, res.Body)
+
+	if res.StatusCode != hc.Status {
+		return errors.Errorf("%s responded with %d, expected %d", hc.Path, res.StatusCode, hc.Status)
+	}
+
+	return nil
+}
+
+// unavailable returns a response for when the circuit is open, including
+// the last lines written to stderr by the app outside of production.
+func (w *worker) unavailable(r *http.Request) *http.Response {
+	retry := w.breaker.Retry()
+	if retry < time.Second {
+		retry = time.Second
+	}
+
+	body := fmt.Sprintf("App is crash-looping, retrying in %s\n", retry.Round(time.Second))
+
+	if os.Getenv("UP_STAGE") != "production" {
+		if s := w.lines.String(); s != "" {
+			body += "\n" + s + "\n"
+		}
+	}
+
+	return &http.Response{
+		Status:     "503 Service Unavailable",
+		StatusCode: http.StatusServiceUnavailable,
+		Proto:      "HTTP/1.1",
+		ProtoMajor: 1,
+		ProtoMinor: 1,
+		Header: http.Header{
+			"Content-Type": {"text/plain; charset=utf-8"},
+			"Retry-After":  {strconv.Itoa(int(retry.Round(time.Second).Seconds()))},
+		},
+		Body:          ioutil.NopCloser(strings.NewReader(body)),
+		ContentLength: int64(len(body)),
+		Request:       r,
+	}
+}
+
+// active returns the active application url and its in-flight request group.
+func (w *worker) active() (*url.URL, *group) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.url, w.inflight
+}
+
+// target returns the active application url.
+func (w *worker) target() *url.URL {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.url
+}
+
+// environment returns the server env variables.
+func (w *worker) environment() []string {
+	v := []string{
+		env("PORT", w.url.Port()),
+		env("UP_RESTARTS", w.restarts),
+		env("UP_WORKER", w.id),
+	}
+
+	if w.config.Proxy.Socket {
+		v = append(v, env("UP_SOCKET", socketPath(w.url.Port())))
+	}
+
+	return v
+}
+
+// listener returns the url the app listens on, which
+// is a Unix domain socket when socket mode is enabled.
+func (w *worker) listener() *url.URL {
+	if w.config.Proxy.Socket {
+		return &url.URL{Scheme: "unix", Path: socketPath(w.url.Port())}
+	}
+
+	return w.url
+}
+
+// startServer the server on a free port.
+func (w *worker) startServer() error {
+	port, err := w.ports.Get(w.id)
+	if err != nil {
+		return errors.Wrap(err, "getting free port")
+	}
+
+	target, err := url.Parse(fmt.Sprintf("http://127.0.0.1:%d", port))
+	if err != nil {
+		return errors.Wrap(err, "parsing url")
+	}
+
+	w.url = target
+	w.inflight = new(group)
+	w.lines.Reset()
+
+	// remove stale sockets
+	if w.config.Proxy.Socket {
+		os.Remove(socketPath(w.url.Port()))
+	}
+
+	w.ctx.WithField("command", w.config.Proxy.Command).WithField("PORT", port).Info("starting app")
+	w.cmd = w.command(w.config.Proxy.Command, w.environment())
+
+	if err := w.cmd.Start(); err != nil {
+		return errors.Wrap(err, "running command")
+	}
+
+	exited := make(chan struct{})
+	w.exited = exited
+
+	go func(cmd *exec.Cmd, socket string) {
+		cmd.Wait()
+		if w.config.Proxy.Socket {
+			os.Remove(socket)
+		}
+		close(exited)
+	}(w.cmd, socketPath(w.url.Port()))
+
+	w.ctx.Info("started app")
+	return nil
+}
+
+// command returns the command for spawning a server.
+func (w *worker) command(s string, env []string) *exec.Cmd {
+	cmd := exec.Command("sh", "-c", s)
+	cmd.Stdout = w.stdout
+	cmd.Stderr = io.MultiWriter(w.stderr, w.lines)
+	cmd.Env = append(os.Environ(), append(env, "PATH=node_modules/.bin:"+os.Getenv("PATH"))...)
+	setpgid(cmd)
+	return cmd
+}
+
+// ports is a registry of the ports allocated to workers,
+// preventing workers started concurrently from being
+// allocated the same free port.
+type ports struct {
+	mu sync.Mutex
+	m  map[int]int
+}
+
+// Get returns a free port for worker id.
+func (p *ports) Get(id int) (int, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.m == nil {
+		p.m = make(map[int]int)
+	}
+
+retry:
+	port, err := freeport.Get()
+	if err != nil {
+		return 0, err
+	}
+
+	for k, v := range p.m {
+		if k != id && v == port {
+			goto retry
+		}
+	}
+
+	p.m[id] = port
+	return port, nil
+}
@@ -31,11 +31,16 @@ func init() {
 	cmd.Action(func(_ *kingpin.ParseContext) error {
 		defer util.Pad()()
 
-		_, p, err := root.Init()
+		c, p, err := root.Init()
 		if err != nil {
 			return errors.Wrap(err, "initializing")
 		}
 
+		// stage overrides
+		if err := c.Override(*stage); err != nil {
+			return errors.Wrap(err, "overriding")
+		}
+
 		stats.Track("Build", nil)
 
 		if err := p.Init(*stage); err != nil {
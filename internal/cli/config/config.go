@@ -4,17 +4,32 @@ import (
 	"encoding/json"
 	"os"
 
-	"github.com/apex/up/internal/cli/root"
-	"github.com/apex/up/internal/stats"
 	"github.com/pkg/errors"
 	"github.com/tj/kingpin"
+
+	"github.com/apex/up"
+	"github.com/apex/up/config"
+	"github.com/apex/up/internal/cli/root"
+	"github.com/apex/up/internal/stats"
 )
 
 func init() {
-	cmd := root.Command("config", "Show configuration after defaults and validation.")
+	cmd := root.Command("config", "Configuration management.")
 	cmd.Example(`up config`, "Show the config.")
+	cmd.Example(`up config schema > up.schema.json`, "Write the JSON Schema of the config.")
+	cmd.Example(`up config lint`, "Report unknown keys and invalid values.")
+
+	show(cmd)
+	schema(cmd)
+	lint(cmd)
+}
+
+// show config.
+func show(cmd *kingpin.Cmd) {
+	c := cmd.Command("show", "Show configuration after defaults and validation.").Default()
+	c.Example(`up config show`, "Show the config.")
 
-	cmd.Action(func(_ *kingpin.ParseContext) error {
+	c.Action(func(_ *kingpin.ParseContext) error {
 		c, _, err := root.Init()
 		if err != nil {
 			return errors.Wrap(err, "initializing")
@@ -22,10 +37,47 @@ func init() {
 
 		stats.Track("Show Config", nil)
 
-		enc := json.NewEncoder(os.Stdout)
-		enc.SetIndent("", "  ")
-		enc.Encode(c)
+		return write(c)
+	})
+}
+
+// schema output.
+func schema(cmd *kingpin.Cmd) {
+	c := cmd.Command("schema", "Show the JSON Schema of the configuration.")
+	c.Example(`up config schema`, "Show the schema.")
+
+	c.Action(func(_ *kingpin.ParseContext) error {
+		stats.Track("Show Config Schema", nil)
+		return write(config.Schema())
+	})
+}
+
+// lint config.
+func lint(cmd *kingpin.Cmd) {
+	c := cmd.Command("lint", "Report unknown keys and invalid values.")
+	c.Example(`up config lint`, "Lint the config.")
+
+	c.Action(func(_ *kingpin.ParseContext) error {
+		problems, err := config.Lint(up.FindConfig())
+		if err != nil {
+			return errors.Wrap(err, "linting")
+		}
+
+		stats.Track("Lint Config", map[string]interface{}{
+			"problems": len(problems),
+		})
+
+		if len(problems) > 0 {
+			return problems
+		}
 
 		return nil
 	})
 }
+
+// write v as indented JSON to stdout.
+func write(v interface{}) error {
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
@@ -10,6 +10,7 @@ import (
 	"github.com/tj/kingpin"
 
 	"github.com/apex/up"
+	"github.com/apex/up/config"
 	"github.com/apex/up/internal/cli/root"
 	"github.com/apex/up/internal/setup"
 	"github.com/apex/up/internal/stats"
@@ -56,6 +57,18 @@ retry:
 		goto retry
 	}
 
+	// lint config, reporting problems by line and column
+	if path := up.FindConfig(); util.Exists(path) {
+		problems, err := config.Lint(path)
+		if err != nil {
+			return errors.Wrap(err, "linting")
+		}
+
+		if len(problems) > 0 {
+			return problems
+		}
+	}
+
 	// unrelated error
 	if err != nil {
 		return errors.Wrap(err, "initializing")
@@ -26,6 +26,7 @@ func init() {
 	cmd.Example(`up domains`, "List purchased domains.")
 	cmd.Example(`up domains check example.com`, "Check availability of a domain.")
 	cmd.Example(`up domains buy`, "Purchase a domain.")
+	cmd.Example(`up domains -s production`, "List domains purchased with the production credentials.")
 	list(cmd)
 	check(cmd)
 	buy(cmd)
@@ -86,11 +87,12 @@ var questions = []*survey.Question{
 // buy a domain.
 func buy(cmd *kingpin.Cmd) {
 	c := cmd.Command("buy", "Purchase a domain.")
+	stage := c.Flag("stage", "Stage whose credentials are used.").Short('s').String()
 
 	c.Action(func(_ *kingpin.ParseContext) error {
 		defer util.Pad()()
 
-		_, p, err := root.Init()
+		_, p, err := root.InitStage(*stage)
 		if err != nil {
 			return errors.Wrap(err, "initializing")
 		}
@@ -119,11 +121,12 @@ func buy(cmd *kingpin.Cmd) {
 func check(cmd *kingpin.Cmd) {
 	c := cmd.Command("check", "Check availability of a domain.")
 	domain := c.Arg("domain", "Domain name.").Required().String()
+	stage := c.Flag("stage", "Stage whose credentials are used.").Short('s').String()
 
 	c.Action(func(_ *kingpin.ParseContext) error {
 		defer util.Pad()()
 
-		_, p, err := root.Init()
+		_, p, err := root.InitStage(*stage)
 		if err != nil {
 			return errors.Wrap(err, "initializing")
 		}
@@ -165,11 +168,12 @@ func check(cmd *kingpin.Cmd) {
 // list domains purchased.
 func list(cmd *kingpin.Cmd) {
 	c := cmd.Command("ls", "List purchased domains.").Alias("list").Default()
+	stage := c.Flag("stage", "Stage whose credentials are used.").Short('s').String()
 
 	c.Action(func(_ *kingpin.ParseContext) error {
 		defer util.Pad()()
 
-		_, p, err := root.Init()
+		_, p, err := root.InitStage(*stage)
 		if err != nil {
 			return errors.Wrap(err, "initializing")
 		}
@@ -1,6 +1,7 @@
 package logs
 
 import (
+	"fmt"
 	"io"
 	"os"
 	"time"
@@ -13,6 +14,7 @@ import (
 	"github.com/apex/up/internal/cli/root"
 	"github.com/apex/up/internal/stats"
 	"github.com/apex/up/internal/util"
+	"github.com/apex/up/internal/validate"
 )
 
 func init() {
@@ -21,6 +23,7 @@ func init() {
 	cmd.Example(`up logs -S 30m`, "Show logs from the past 30 minutes.")
 	cmd.Example(`up logs -S 5h`, "Show logs from the past 5 hours.")
 	cmd.Example(`up logs -f`, "Show live log output.")
+	cmd.Example(`up logs -s production`, "Show production logs, using the production credentials.")
 	cmd.Example(`up logs error`, "Show error logs.")
 	cmd.Example(`up logs 'level != "info"'`, "Show non-info logs.")
 	cmd.Example(`up logs 'production (warn or error)'`, "Show 4xx and 5xx responses in production.")
@@ -40,6 +43,7 @@ func init() {
 	follow := cmd.Flag("follow", "Follow or tail the live logs.").Short('f').Bool()
 	since := cmd.Flag("since", "Show logs since duration (30s, 5m, 2h, 1h30m, 3d, 1M).").Short('S').Default("1d").String()
 	expand := cmd.Flag("expand", "Show expanded logs.").Short('e').Bool()
+	stage := cmd.Flag("stage", "Target stage name.").Short('s').String()
 
 	cmd.Action(func(_ *kingpin.ParseContext) error {
 		c, p, err := root.Init()
@@ -47,6 +51,17 @@ func init() {
 			return errors.Wrap(err, "initializing")
 		}
 
+		// stage overrides
+		if *stage != "" {
+			if err := validate.List(*stage, c.Stages.RemoteNames()); err != nil {
+				return err
+			}
+
+			if err := c.Override(*stage); err != nil {
+				return errors.Wrap(err, "overriding")
+			}
+		}
+
 		var s time.Duration
 
 		if *since != "" {
@@ -62,12 +77,17 @@ func init() {
 
 		q := *query
 
+		if *stage != "" {
+			q = stageQuery(*stage, q)
+		}
+
 		stats.Track("Logs", map[string]interface{}{
 			"query":        q != "",
 			"query_length": len(q),
 			"follow":       *follow,
 			"since":        s.Round(time.Second),
 			"expand":       *expand,
+			"stage":        *stage != "",
 		})
 
 		logs := p.Logs(up.LogsConfig{
@@ -86,3 +106,14 @@ func init() {
 		return nil
 	})
 }
+
+// stageQuery returns the query restricted to the stage.
+func stageQuery(stage, query string) string {
+	s := fmt.Sprintf("stage = %q", stage)
+
+	if query == "" {
+		return s
+	}
+
+	return fmt.Sprintf("%s (%s)", s, query)
+}
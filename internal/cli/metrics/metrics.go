@@ -25,6 +25,11 @@ func init() {
 			return errors.Wrap(err, "initializing")
 		}
 
+		// stage overrides
+		if err := c.Override(*stage); err != nil {
+			return errors.Wrap(err, "overriding")
+		}
+
 		s, err := util.ParseDuration(*since)
 		if err != nil {
 			return errors.Wrap(err, "parsing --since duration")
@@ -24,6 +24,11 @@ func init() {
 			return errors.Wrap(err, "initializing")
 		}
 
+		// stage overrides
+		if err := c.Override(*stage); err != nil {
+			return errors.Wrap(err, "overriding")
+		}
+
 		region := c.Regions[0]
 
 		stats.Track("Prune", map[string]interface{}{
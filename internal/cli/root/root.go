@@ -12,6 +12,7 @@ import (
 
 	"github.com/apex/up"
 	"github.com/apex/up/internal/util"
+	"github.com/apex/up/internal/validate"
 	"github.com/apex/up/platform/event"
 	"github.com/apex/up/platform/lambda"
 	"github.com/apex/up/reporter"
@@ -26,6 +27,29 @@ var Command = Cmd.Command
 // Init function.
 var Init func() (*up.Config, *up.Project, error)
 
+// InitStage initializes like Init, overriding the config with the
+// remote stage when present, so that its credentials are used.
+func InitStage(stage string) (*up.Config, *up.Project, error) {
+	c, p, err := Init()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if stage == "" {
+		return c, p, nil
+	}
+
+	if err := validate.List(stage, c.Stages.RemoteNames()); err != nil {
+		return nil, nil, err
+	}
+
+	if err := c.Override(stage); err != nil {
+		return nil, nil, errors.Wrap(err, "overriding")
+	}
+
+	return c, p, nil
+}
+
 func init() {
 	log.SetHandler(cli.Default)
 
@@ -53,13 +77,13 @@ func init() {
 		}
 
 		Init = func() (*up.Config, *up.Project, error) {
-			c, err := up.ReadConfig("up.json")
+			c, err := up.ReadConfig(up.FindConfig())
 			if err != nil {
 				return nil, nil, errors.Wrap(err, "reading config")
 			}
 
 			if *region != "" {
-				c.Regions = []string{*region}
+				c.TargetRegion(*region)
 			}
 
 			events := make(event.Events)
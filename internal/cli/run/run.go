@@ -17,11 +17,16 @@ func init() {
 	stage := cmd.Flag("stage", "Target stage name.").Short('s').Default("staging").String()
 
 	cmd.Action(func(_ *kingpin.ParseContext) error {
-		_, p, err := root.Init()
+		c, p, err := root.Init()
 		if err != nil {
 			return errors.Wrap(err, "initializing")
 		}
 
+		// stage overrides
+		if err := c.Override(*stage); err != nil {
+			return errors.Wrap(err, "overriding")
+		}
+
 		defer util.Pad()()
 
 		stats.Track("Hook", map[string]interface{}{
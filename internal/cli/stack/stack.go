@@ -19,6 +19,7 @@ func init() {
 	cmd.Example(`up stack plan`, "Show resource changes.")
 	cmd.Example(`up stack apply`, "Apply resource changes.")
 	cmd.Example(`up stack delete`, "Delete the stack resources.")
+	cmd.Example(`up stack plan -s production`, "Show resource changes using the production credentials.")
 
 	plan(cmd)
 	apply(cmd)
@@ -30,9 +31,10 @@ func init() {
 func plan(cmd *kingpin.Cmd) {
 	c := cmd.Command("plan", "Plan configuration changes.")
 	c.Example(`up stack plan`, "Show changes planned.")
+	stage := c.Flag("stage", "Stage whose credentials are used.").Short('s').String()
 
 	c.Action(func(_ *kingpin.ParseContext) error {
-		c, p, err := root.Init()
+		c, p, err := root.InitStage(*stage)
 		if err != nil {
 			return errors.Wrap(err, "initializing")
 		}
@@ -58,9 +60,10 @@ func plan(cmd *kingpin.Cmd) {
 func apply(cmd *kingpin.Cmd) {
 	c := cmd.Command("apply", "Apply configuration changes.")
 	c.Example(`up stack apply`, "Apply the changes of the previous plan.")
+	stage := c.Flag("stage", "Stage whose credentials are used.").Short('s').String()
 
 	c.Action(func(_ *kingpin.ParseContext) error {
-		c, p, err := root.Init()
+		c, p, err := root.InitStage(*stage)
 		if err != nil {
 			return errors.Wrap(err, "initializing")
 		}
@@ -86,9 +89,10 @@ func delete(cmd *kingpin.Cmd) {
 
 	force := c.Flag("force", "Skip the confirmation prompt.").Short('f').Bool()
 	async := c.Flag("async", "Perform deletion asynchronously.").Short('a').Bool()
+	stage := c.Flag("stage", "Stage whose credentials are used.").Short('s').String()
 
 	c.Action(func(_ *kingpin.ParseContext) error {
-		c, p, err := root.Init()
+		c, p, err := root.InitStage(*stage)
 		if err != nil {
 			return errors.Wrap(err, "initializing")
 		}
@@ -127,9 +131,10 @@ func delete(cmd *kingpin.Cmd) {
 // status of the stack.
 func status(cmd *kingpin.Cmd) {
 	c := cmd.Command("status", "Show status of resources.").Default()
+	stage := c.Flag("stage", "Stage whose credentials are used.").Short('s').String()
 
 	c.Action(func(_ *kingpin.ParseContext) error {
-		c, p, err := root.Init()
+		c, p, err := root.InitStage(*stage)
 		if err != nil {
 			return errors.Wrap(err, "initializing")
 		}
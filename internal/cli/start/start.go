@@ -2,6 +2,7 @@ package start
 
 import (
 	"fmt"
+	"io"
 	"net"
 	"net/http"
 	"os"
@@ -14,6 +15,7 @@ import (
 	"github.com/apex/up/handler"
 	"github.com/apex/up/internal/cli/root"
 	"github.com/apex/up/internal/logs/text"
+	"github.com/apex/up/internal/signal"
 	"github.com/apex/up/internal/stats"
 )
 
@@ -64,6 +66,11 @@ func init() {
 			return errors.Wrap(err, "selecting handler")
 		}
 
+		// stop the app and its child processes on exit
+		if c, ok := h.(io.Closer); ok {
+			signal.Add(c.Close)
+		}
+
 		h, err = handler.New(c, h)
 		if err != nil {
 			return errors.Wrap(err, "initializing handler")
@@ -23,6 +23,7 @@ func init() {
 	cmd.Example(`up url -s production`, "Show the production endpoint.")
 	cmd.Example(`up url -o -s production`, "Open the production endpoint in the browser.")
 	cmd.Example(`up url -c -s production`, "Copy the production endpoint to the clipboard.")
+	cmd.Example(`up url -s production --region eu-west-1`, "Show the production endpoint in a single region.")
 
 	stage := cmd.Flag("stage", "Target stage name.").Short('s').Default("staging").String()
 	open := cmd.Flag("open", "Open endpoint in the browser.").Short('o').Bool()
@@ -34,32 +35,43 @@ func init() {
 			return errors.Wrap(err, "initializing")
 		}
 
-		region := c.Regions[0]
-
 		stats.Track("URL", map[string]interface{}{
-			"region": region,
-			"stage":  *stage,
-			"open":   *open,
-			"copy":   *copy,
+			"regions": c.Regions,
+			"stage":   *stage,
+			"open":    *open,
+			"copy":    *copy,
 		})
 
 		if err := validate.List(*stage, c.Stages.RemoteNames()); err != nil {
 			return err
 		}
 
-		url, err := p.URL(region, *stage)
-		if err != nil {
-			return err
+		// stage overrides
+		if err := c.Override(*stage); err != nil {
+			return errors.Wrap(err, "overriding")
+		}
+
+		var urls []string
+
+		for _, region := range c.Regions {
+			url, err := p.URL(region, *stage)
+			if err != nil {
+				return errors.Wrap(err, region)
+			}
+
+			urls = append(urls, url)
 		}
 
 		switch {
 		case *open:
-			browser.OpenURL(url)
+			browser.OpenURL(urls[0])
 		case *copy:
-			clipboard.Write(url)
+			clipboard.Write(urls[0])
 			util.LogPad("Copied to clipboard!")
 		default:
-			fmt.Println(url)
+			for _, url := range urls {
+				fmt.Println(url)
+			}
 		}
 
 		return nil
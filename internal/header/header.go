@@ -43,7 +43,11 @@ func Merge(a, b Rules) Rules {
 	r := make(Rules)
 
 	for path, fields := range a {
-		r[path] = fields
+		r[path] = make(Fields)
+
+		for name, val := range fields {
+			r[path][name] = val
+		}
 	}
 
 	for path, fields := range b {
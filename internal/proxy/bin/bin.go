@@ -1,4 +1,5 @@
-//go:generate sh -c "GOOS=linux GOARCH=amd64 go build -o up-proxy ../../../cmd/up-proxy/main.go"
+//go:generate sh -c "GOOS=linux GOARCH=amd64 go build -o up-proxy-x86_64 ../../../cmd/up-proxy/main.go"
+//go:generate sh -c "GOOS=linux GOARCH=arm64 go build -o up-proxy-arm64 ../../../cmd/up-proxy/main.go"
 //go:generate go-bindata -modtime 0 -pkg bin -o bin_assets.go .
 
 package bin
@@ -16,7 +16,30 @@ type Identity struct {
 	CognitoAuthenticationProvider string `json:"cognitoAuthenticationProvider"`
 }
 
-// RequestContext is the contextual information provided by API Gateway.
+// HTTP is the HTTP request information provided by HTTP APIs.
+type HTTP struct {
+	Method    string `json:"method"`
+	Path      string `json:"path"`
+	Protocol  string `json:"protocol"`
+	SourceIP  string `json:"sourceIp"`
+	UserAgent string `json:"userAgent"`
+}
+
+// Cron is the request of a scheduled event, provided by EventBridge rules.
+type Cron struct {
+	Name   string `json:"name"`
+	Method string `json:"method"`
+	Path   string `json:"path"`
+	Body   string `json:"body"`
+}
+
+// ELB is the load balancer information provided by ALB target groups.
+type ELB struct {
+	TargetGroupArn string `json:"targetGroupArn"`
+}
+
+// RequestContext is the contextual information provided by API Gateway,
+// or the target group of an Application Load Balancer.
 type RequestContext struct {
 	APIID        string                 `json:"apiId"`
 	ResourceID   string                 `json:"resourceId"`
@@ -27,26 +50,60 @@ type RequestContext struct {
 	Stage        string                 `json:"stage"`
 	Identity     Identity               `json:"identity"`
 	Authorizer   map[string]interface{} `json:"authorizer"`
+	HTTP         *HTTP                  `json:"http,omitempty"`
+	ELB          *ELB                   `json:"elb,omitempty"`
 }
 
-// Input is the input provided by API Gateway.
+// Input is the input provided by API Gateway. REST APIs use the
+// payload format 1.0, which provides every value of repeated header
+// fields and query parameters in the multi-value maps, while HTTP APIs
+// use the 2.0 format, which provides the raw path, query string, and cookies.
+// Application Load Balancers use the 1.0 format without decoding the query.
+// Scheduled events of crons provide the request in the detail.
 type Input struct {
-	HTTPMethod            string
-	Headers               map[string]string
-	Resource              string
-	PathParameters        map[string]string
-	Path                  string
-	QueryStringParameters map[string]string
-	Body                  string
-	IsBase64Encoded       bool
-	StageVariables        map[string]string
-	RequestContext        RequestContext
-}
-
-// Output is the output expected by API Gateway.
+	Version                         string
+	HTTPMethod                      string
+	Headers                         map[string]string
+	MultiValueHeaders               map[string][]string
+	Resource                        string
+	PathParameters                  map[string]string
+	Path                            string
+	QueryStringParameters           map[string]string
+	MultiValueQueryStringParameters map[string][]string
+	RawPath                         string
+	RawQueryString                  string
+	Cookies                         []string
+	Body                            string
+	IsBase64Encoded                 bool
+	StageVariables                  map[string]string
+	RequestContext                  RequestContext
+	Source                          string
+	Detail                          *Cron
+}
+
+// IsV2 returns true if the input uses the payload format 2.0.
+func (i *Input) IsV2() bool {
+	return i.Version == "2.0"
+}
+
+// IsCron returns true if the input is a scheduled event of a cron.
+func (i *Input) IsCron() bool {
+	return i.Source == "up.cron" && i.Detail != nil
+}
+
+// IsALB returns true if the input is from an Application Load Balancer.
+func (i *Input) IsALB() bool {
+	return i.RequestContext.ELB != nil
+}
+
+// Output is the output expected by API Gateway, or an Application
+// Load Balancer, which requires the status description.
 type Output struct {
-	StatusCode      int               `json:"statusCode"`
-	Headers         map[string]string `json:"headers,omitempty"`
-	Body            string            `json:"body,omitempty"`
-	IsBase64Encoded bool              `json:"isBase64Encoded"`
+	StatusCode        int                 `json:"statusCode"`
+	StatusDescription string              `json:"statusDescription,omitempty"`
+	Headers           map[string]string   `json:"headers,omitempty"`
+	MultiValueHeaders map[string][]string `json:"multiValueHeaders,omitempty"`
+	Cookies           []string            `json:"cookies,omitempty"`
+	Body              string              `json:"body,omitempty"`
+	IsBase64Encoded   bool                `json:"isBase64Encoded"`
 }
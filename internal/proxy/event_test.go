@@ -230,6 +230,113 @@ var postEventBinary = `{
   "isBase64Encoded": true
 }`
 
+var getEventV2 = `{
+  "version": "2.0",
+  "routeKey": "$default",
+  "rawPath": "/pets/tobi%20ferret",
+  "rawQueryString": "format=json&tags=a&tags=b",
+  "cookies": [
+    "session=abc",
+    "theme=dark"
+  ],
+  "headers": {
+    "accept": "*/*",
+    "content-length": "0",
+    "host": "apex-ping.com",
+    "user-agent": "curl/7.48.0",
+    "x-forwarded-for": "207.102.57.26",
+    "x-forwarded-port": "443",
+    "x-forwarded-proto": "https"
+  },
+  "queryStringParameters": {
+    "format": "json",
+    "tags": "a,b"
+  },
+  "requestContext": {
+    "accountId": "111111111",
+    "apiId": "iwcgwgigca",
+    "domainName": "apex-ping.com",
+    "http": {
+      "method": "GET",
+      "path": "/pets/tobi ferret",
+      "protocol": "HTTP/1.1",
+      "sourceIp": "207.102.57.26",
+      "userAgent": "curl/7.48.0"
+    },
+    "requestId": "JKJaXmPLvHcESHA=",
+    "routeKey": "$default",
+    "stage": "$default",
+    "time": "10/Mar/2020:05:16:23 +0000",
+    "timeEpoch": 1583817383220
+  },
+  "isBase64Encoded": false
+}`
+
+var getEventV2Stage = `{
+  "version": "2.0",
+  "routeKey": "$default",
+  "rawPath": "/production/pets/tobi",
+  "rawQueryString": "format=json",
+  "headers": {
+    "host": "iwcgwgigca.execute-api.us-west-2.amazonaws.com",
+    "x-forwarded-for": "207.102.57.26"
+  },
+  "requestContext": {
+    "accountId": "111111111",
+    "apiId": "iwcgwgigca",
+    "domainName": "iwcgwgigca.execute-api.us-west-2.amazonaws.com",
+    "http": {
+      "method": "GET",
+      "path": "/production/pets/tobi",
+      "protocol": "HTTP/1.1",
+      "sourceIp": "207.102.57.26",
+      "userAgent": "curl/7.48.0"
+    },
+    "requestId": "JKJaXmPLvHcESHA=",
+    "routeKey": "$default",
+    "stage": "production",
+    "time": "10/Mar/2020:05:16:23 +0000",
+    "timeEpoch": 1583817383220
+  },
+  "isBase64Encoded": false
+}`
+
+var getEventALB = `{
+  "requestContext": {
+    "elb": {
+      "targetGroupArn": "arn:aws:elasticloadbalancing:us-west-2:111111111:targetgroup/app-production/73e2d6bc24d8a067"
+    }
+  },
+  "httpMethod": "GET",
+  "path": "/pets/tobi",
+  "multiValueQueryStringParameters": {
+    "name": ["tobi%20ferret"],
+    "tags": ["a%2Cb", "c"]
+  },
+  "multiValueHeaders": {
+    "accept": ["text/html", "application/json"],
+    "host": ["internal-app-123.us-west-2.elb.amazonaws.com"],
+    "user-agent": ["curl/7.48.0"],
+    "x-amzn-trace-id": ["Root=1-5c536348-3d683b8b04734faae651f476"],
+    "x-forwarded-for": ["10.0.1.20, 10.0.0.5"],
+    "x-forwarded-port": ["80"],
+    "x-forwarded-proto": ["http"]
+  },
+  "body": "",
+  "isBase64Encoded": false
+}`
+
+var cronEvent = `{
+  "source": "up.cron",
+  "detail-type": "Scheduled Event",
+  "detail": {
+    "name": "cleanup",
+    "method": "POST",
+    "path": "/tasks/cleanup?older_than=7d",
+    "body": "{ \"dry\": false }"
+  }
+}`
+
 func output(v interface{}) {
 	b, _ := json.MarshalIndent(v, "", "  ")
 	fmt.Printf("%s\n", string(b))
@@ -241,6 +348,7 @@ func ExampleInput_get() {
 	output(in)
 	// Output:
 	// {
+	//   "Version": "",
 	//   "HTTPMethod": "GET",
 	//   "Headers": {
 	//     "Accept": "*/*",
@@ -259,6 +367,7 @@ func ExampleInput_get() {
 	//     "X-Forwarded-Port": "443",
 	//     "X-Forwarded-Proto": "https"
 	//   },
+	//   "MultiValueHeaders": null,
 	//   "Resource": "/{proxy+}",
 	//   "PathParameters": {
 	//     "proxy": "pets/tobi"
@@ -267,6 +376,10 @@ func ExampleInput_get() {
 	//   "QueryStringParameters": {
 	//     "format": "json"
 	//   },
+	//   "MultiValueQueryStringParameters": null,
+	//   "RawPath": "",
+	//   "RawQueryString": "",
+	//   "Cookies": null,
 	//   "Body": "",
 	//   "IsBase64Encoded": false,
 	//   "StageVariables": {
@@ -293,7 +406,9 @@ func ExampleInput_get() {
 	//       "cognitoAuthenticationProvider": ""
 	//     },
 	//     "authorizer": null
-	//   }
+	//   },
+	//   "Source": "",
+	//   "Detail": null
 	// }
 }
 
@@ -303,6 +418,7 @@ func ExampleInput_post() {
 	output(in)
 	// Output:
 	// {
+	//   "Version": "",
 	//   "HTTPMethod": "POST",
 	//   "Headers": {
 	//     "Accept": "*/*",
@@ -322,12 +438,17 @@ func ExampleInput_post() {
 	//     "X-Forwarded-Proto": "https",
 	//     "content-type": "application/json"
 	//   },
+	//   "MultiValueHeaders": null,
 	//   "Resource": "/{proxy+}",
 	//   "PathParameters": {
 	//     "proxy": "pets/tobi"
 	//   },
 	//   "Path": "/pets/tobi",
 	//   "QueryStringParameters": null,
+	//   "MultiValueQueryStringParameters": null,
+	//   "RawPath": "",
+	//   "RawQueryString": "",
+	//   "Cookies": null,
 	//   "Body": "{ \"name\": \"Tobi\" }",
 	//   "IsBase64Encoded": false,
 	//   "StageVariables": null,
@@ -352,6 +473,8 @@ func ExampleInput_post() {
 	//       "cognitoAuthenticationProvider": ""
 	//     },
 	//     "authorizer": null
-	//   }
+	//   },
+	//   "Source": "",
+	//   "Detail": null
 	// }
 }
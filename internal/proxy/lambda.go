@@ -2,30 +2,58 @@
 package proxy
 
 import (
+	"context"
 	"encoding/json"
 	"net/http"
+	"time"
 
 	"github.com/apex/go-apex"
 	"github.com/pkg/errors"
 )
 
-// NewHandler returns an apex.Handler.
-func NewHandler(h http.Handler) apex.Handler {
+// NewHandler returns an apex.Handler, accepting events of REST APIs and
+// HTTP APIs, detected by the payload format version, of Application
+// Load Balancer target groups, and of crons. Requests have a context
+// deadline of the function timeout, approximating the remaining time of the
+// invocation, see Handle for the exact deadline.
+func NewHandler(h http.Handler, timeout time.Duration) apex.Handler {
 	return apex.HandlerFunc(func(event json.RawMessage, ctx *apex.Context) (interface{}, error) {
-		e := new(Input)
+		return handle(h, event, time.Now().Add(timeout))
+	})
+}
 
-		err := json.Unmarshal(event, e)
-		if err != nil {
-			return nil, errors.Wrap(err, "parsing proxy event")
-		}
+// handle serves the event with the given request deadline, buffering the response.
+func handle(h http.Handler, event json.RawMessage, deadline time.Time) (interface{}, error) {
+	c, cancel := context.WithDeadline(context.Background(), deadline)
+	defer cancel()
 
-		req, err := NewRequest(e)
-		if err != nil {
-			return nil, errors.Wrap(err, "creating new request from event")
-		}
+	e := new(Input)
 
-		res := NewResponse()
-		h.ServeHTTP(res, req)
-		return res.End(), nil
-	})
+	err := json.Unmarshal(event, e)
+	if err != nil {
+		return nil, errors.Wrap(err, "parsing proxy event")
+	}
+
+	req, err := NewRequest(e)
+	if err != nil {
+		return nil, errors.Wrap(err, "creating new request from event")
+	}
+
+	res := NewResponse()
+	switch {
+	case e.IsV2():
+		res = NewResponseV2()
+	case e.IsALB():
+		res = NewResponseALB(e.MultiValueHeaders != nil)
+	}
+
+	h.ServeHTTP(res, req.WithContext(c))
+	out := res.End()
+
+	// failed crons are retried by EventBridge
+	if e.IsCron() && out.StatusCode >= 500 {
+		return nil, errors.Errorf("cron %q responded with %d", e.Detail.Name, out.StatusCode)
+	}
+
+	return out, nil
 }
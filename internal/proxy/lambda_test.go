@@ -0,0 +1,31 @@
+package proxy
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/tj/assert"
+)
+
+func TestNewHandler_cron(t *testing.T) {
+	t.Run("ok", func(t *testing.T) {
+		h := NewHandler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusNoContent)
+		}), time.Second)
+
+		v, err := h.Handle(json.RawMessage(cronEvent), nil)
+		assert.NoError(t, err, "handle")
+		assert.Equal(t, 204, v.(Output).StatusCode)
+	})
+
+	t.Run("error", func(t *testing.T) {
+		h := NewHandler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			http.Error(w, "Database unavailable", http.StatusServiceUnavailable)
+		}), time.Second)
+
+		_, err := h.Handle(json.RawMessage(cronEvent), nil)
+		assert.EqualError(t, err, `cron "cleanup" responded with 503`)
+	})
+}
@@ -5,26 +5,34 @@ import (
 	"encoding/json"
 	"net/http"
 	"net/url"
+	"os"
 	"strconv"
 	"strings"
 
 	"github.com/pkg/errors"
 )
 
-// NewRequest returns a new http.Request from the given Lambda event.
+// NewRequest returns a new http.Request from the given Lambda event,
+// in either the payload format 1.0 or 2.0, from a load balancer, or
+// from the scheduled event of a cron.
 func NewRequest(e *Input) (*http.Request, error) {
-	// path
-	u, err := url.Parse(e.Path)
-	if err != nil {
-		return nil, errors.Wrap(err, "parsing path")
+	if e.IsCron() {
+		return newCronRequest(e.Detail)
 	}
 
-	// querystring
-	q := u.Query()
-	for k, v := range e.QueryStringParameters {
-		q.Set(k, v)
+	method := e.HTTPMethod
+	remoteAddr := e.RequestContext.Identity.SourceIP
+
+	if e.IsV2() && e.RequestContext.HTTP != nil {
+		method = e.RequestContext.HTTP.Method
+		remoteAddr = e.RequestContext.HTTP.SourceIP
+	}
+
+	// url
+	u, err := requestURL(e)
+	if err != nil {
+		return nil, err
 	}
-	u.RawQuery = q.Encode()
 
 	// base64 encoded body
 	body := e.Body
@@ -37,24 +45,48 @@ func NewRequest(e *Input) (*http.Request, error) {
 	}
 
 	// new request
-	req, err := http.NewRequest(e.HTTPMethod, u.String(), strings.NewReader(body))
+	req, err := http.NewRequest(method, u.String(), strings.NewReader(body))
 	if err != nil {
 		return nil, errors.Wrap(err, "creating request")
 	}
 
 	// remote addr
-	req.RemoteAddr = e.RequestContext.Identity.SourceIP
+	req.RemoteAddr = remoteAddr
 
 	// header fields
 	for k, v := range e.Headers {
 		req.Header.Set(k, v)
 	}
 
+	// multi-value header fields
+	for k, v := range e.MultiValueHeaders {
+		req.Header.Del(k)
+		for _, s := range v {
+			req.Header.Add(k, s)
+		}
+	}
+
+	// only crons may mark requests
+	req.Header.Del("X-Up-Cron")
+
+	// cookies
+	if len(e.Cookies) > 0 {
+		req.Header.Set("Cookie", strings.Join(e.Cookies, "; "))
+	}
+
 	// content-length
 	if req.Header.Get("Content-Length") == "" && body != "" {
 		req.Header.Set("Content-Length", strconv.Itoa(len(body)))
 	}
 
+	// load balancers provide the client address and
+	// request id only in the forwarded header fields
+	if e.IsALB() {
+		req.RemoteAddr = forwardedFor(req.Header.Get("X-Forwarded-For"))
+		e.RequestContext.RequestID = req.Header.Get("X-Amzn-Trace-Id")
+		e.RequestContext.Stage = os.Getenv("UP_STAGE")
+	}
+
 	// custom fields
 	b, _ := json.Marshal(e.RequestContext)
 	req.Header.Set("X-Context", string(b))
@@ -67,3 +99,118 @@ func NewRequest(e *Input) (*http.Request, error) {
 
 	return req, nil
 }
+
+// rawPath returns the raw path of a payload format 2.0 event. The raw path
+// of requests to the default endpoint of an HTTP API is prefixed with the
+// name of the stage, which is removed unless it is the $default stage.
+func rawPath(e *Input) string {
+	s := e.RequestContext.Stage
+	if s == "" || s == "$default" {
+		return e.RawPath
+	}
+
+	prefix := "/" + s
+
+	if e.RawPath == prefix {
+		return "/"
+	}
+
+	if strings.HasPrefix(e.RawPath, prefix+"/") {
+		return strings.TrimPrefix(e.RawPath, prefix)
+	}
+
+	return e.RawPath
+}
+
+// newCronRequest returns a new internal http.Request of
+// the cron, marked with the X-Up-Cron header field.
+func newCronRequest(c *Cron) (*http.Request, error) {
+	req, err := http.NewRequest(c.Method, c.Path, strings.NewReader(c.Body))
+	if err != nil {
+		return nil, errors.Wrap(err, "creating request")
+	}
+
+	req.RemoteAddr = "127.0.0.1"
+	req.Header.Set("X-Up-Cron", c.Name)
+	req.Header.Set("X-Stage", os.Getenv("UP_STAGE"))
+
+	if c.Body != "" {
+		req.Header.Set("Content-Length", strconv.Itoa(len(c.Body)))
+	}
+
+	return req, nil
+}
+
+// requestURL returns the url of the event. The payload format 2.0
+// provides the raw query string, while 1.0 provides the parameters.
+func requestURL(e *Input) (*url.URL, error) {
+	if e.IsV2() {
+		u, err := url.Parse(rawPath(e))
+		if err != nil {
+			return nil, errors.Wrap(err, "parsing path")
+		}
+
+		u.RawQuery = e.RawQueryString
+		return u, nil
+	}
+
+	// path
+	u, err := url.Parse(e.Path)
+	if err != nil {
+		return nil, errors.Wrap(err, "parsing path")
+	}
+
+	// querystring
+	q := u.Query()
+	for k, v := range e.QueryStringParameters {
+		q.Set(k, v)
+	}
+
+	// multi-value querystring
+	for k, v := range e.MultiValueQueryStringParameters {
+		q[k] = v
+	}
+
+	// load balancers do not decode the querystring
+	if e.IsALB() {
+		q = unescapeQuery(q)
+	}
+
+	u.RawQuery = q.Encode()
+
+	return u, nil
+}
+
+// unescapeQuery returns the decoded query, values which
+// are not valid escape sequences are used as-is.
+func unescapeQuery(q url.Values) url.Values {
+	v := make(url.Values)
+
+	for key, values := range q {
+		key = unescape(key)
+		for _, s := range values {
+			v.Add(key, unescape(s))
+		}
+	}
+
+	return v
+}
+
+// unescape returns the decoded s, or s when invalid.
+func unescape(s string) string {
+	v, err := url.QueryUnescape(s)
+	if err != nil {
+		return s
+	}
+
+	return v
+}
+
+// forwardedFor returns the client address of the X-Forwarded-For field.
+func forwardedFor(s string) string {
+	if i := strings.Index(s, ","); i != -1 {
+		s = s[:i]
+	}
+
+	return strings.TrimSpace(s)
+}
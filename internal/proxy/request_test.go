@@ -63,6 +63,63 @@ func TestNewRequest(t *testing.T) {
 		assert.Equal(t, `Hello World`, string(b))
 	})
 
+	t.Run("GET multi-value", func(t *testing.T) {
+		in := Input{
+			HTTPMethod: "GET",
+			Path:       "/pets",
+			Headers: map[string]string{
+				"Host":   "apex-ping.com",
+				"Accept": "application/json",
+			},
+			MultiValueHeaders: map[string][]string{
+				"Host":   {"apex-ping.com"},
+				"Accept": {"text/html", "application/json"},
+			},
+			QueryStringParameters: map[string]string{
+				"id": "2",
+			},
+			MultiValueQueryStringParameters: map[string][]string{
+				"id": {"1", "2"},
+			},
+		}
+
+		req, err := NewRequest(&in)
+		assert.NoError(t, err, "new request")
+
+		assert.Equal(t, "apex-ping.com", req.Host)
+		assert.Equal(t, []string{"text/html", "application/json"}, req.Header["Accept"])
+		assert.Equal(t, []string{"1", "2"}, req.URL.Query()["id"])
+	})
+
+	t.Run("cron", func(t *testing.T) {
+		var in Input
+		err := json.Unmarshal([]byte(cronEvent), &in)
+		assert.NoError(t, err, "unmarshal")
+
+		req, err := NewRequest(&in)
+		assert.NoError(t, err, "new request")
+
+		assert.Equal(t, "POST", req.Method)
+		assert.Equal(t, "/tasks/cleanup", req.URL.Path)
+		assert.Equal(t, "7d", req.URL.Query().Get("older_than"))
+		assert.Equal(t, "cleanup", req.Header.Get("X-Up-Cron"))
+
+		b, err := ioutil.ReadAll(req.Body)
+		assert.NoError(t, err, "read body")
+		assert.Equal(t, `{ "dry": false }`, string(b))
+	})
+
+	t.Run("spoofed cron", func(t *testing.T) {
+		var in Input
+		err := json.Unmarshal([]byte(getEvent), &in)
+		assert.NoError(t, err, "unmarshal")
+		in.Headers["X-Up-Cron"] = "cleanup"
+
+		req, err := NewRequest(&in)
+		assert.NoError(t, err, "new request")
+		assert.Empty(t, req.Header.Get("X-Up-Cron"))
+	})
+
 	t.Run("Basic Auth", func(t *testing.T) {
 		var in Input
 		err := json.Unmarshal([]byte(getEventBasicAuth), &in)
@@ -78,4 +135,64 @@ func TestNewRequest(t *testing.T) {
 		assert.Equal(t, "ferret", pass)
 		assert.True(t, ok)
 	})
+	t.Run("GET v2", func(t *testing.T) {
+		var in Input
+		err := json.Unmarshal([]byte(getEventV2), &in)
+		assert.NoError(t, err, "unmarshal")
+
+		req, err := NewRequest(&in)
+		assert.NoError(t, err, "new request")
+
+		assert.Equal(t, "GET", req.Method)
+		assert.Equal(t, "apex-ping.com", req.Host)
+		assert.Equal(t, "/pets/tobi ferret", req.URL.Path)
+		assert.Equal(t, "format=json&tags=a&tags=b", req.URL.RawQuery)
+		assert.Equal(t, "207.102.57.26", req.RemoteAddr)
+		assert.Equal(t, "session=abc; theme=dark", req.Header.Get("Cookie"))
+		assert.Equal(t, "JKJaXmPLvHcESHA=", req.Header.Get("X-Request-Id"))
+
+		c, err := req.Cookie("theme")
+		assert.NoError(t, err, "cookie")
+		assert.Equal(t, "dark", c.Value)
+	})
+
+	t.Run("GET v2 named stage", func(t *testing.T) {
+		var in Input
+		err := json.Unmarshal([]byte(getEventV2Stage), &in)
+		assert.NoError(t, err, "unmarshal")
+
+		req, err := NewRequest(&in)
+		assert.NoError(t, err, "new request")
+
+		assert.Equal(t, "/pets/tobi", req.URL.Path)
+		assert.Equal(t, "format=json", req.URL.RawQuery)
+
+		in.RawPath = "/production"
+		req, err = NewRequest(&in)
+		assert.NoError(t, err, "new request")
+		assert.Equal(t, "/", req.URL.Path)
+
+		in.RawPath = "/productions"
+		req, err = NewRequest(&in)
+		assert.NoError(t, err, "new request")
+		assert.Equal(t, "/productions", req.URL.Path)
+	})
+
+	t.Run("GET alb", func(t *testing.T) {
+		var in Input
+		err := json.Unmarshal([]byte(getEventALB), &in)
+		assert.NoError(t, err, "unmarshal")
+
+		req, err := NewRequest(&in)
+		assert.NoError(t, err, "new request")
+
+		assert.Equal(t, "GET", req.Method)
+		assert.Equal(t, "internal-app-123.us-west-2.elb.amazonaws.com", req.Host)
+		assert.Equal(t, "/pets/tobi", req.URL.Path)
+		assert.Equal(t, "tobi ferret", req.URL.Query().Get("name"))
+		assert.Equal(t, []string{"a,b", "c"}, req.URL.Query()["tags"])
+		assert.Equal(t, []string{"text/html", "application/json"}, req.Header["Accept"])
+		assert.Equal(t, "10.0.1.20", req.RemoteAddr)
+		assert.Equal(t, "Root=1-5c536348-3d683b8b04734faae651f476", req.Header.Get("X-Request-Id"))
+	})
 }
@@ -3,6 +3,7 @@ package proxy
 import (
 	"bytes"
 	"encoding/base64"
+	"fmt"
 	"mime"
 	"net/http"
 	"strings"
@@ -13,6 +14,9 @@ import (
 // ResponseWriter implements the http.ResponseWriter interface
 // in order to support the API Gateway Lambda HTTP "protocol".
 type ResponseWriter struct {
+	v2          bool
+	alb         bool
+	singleValue bool
 	out         Output
 	buf         bytes.Buffer
 	header      http.Header
@@ -24,6 +28,19 @@ func NewResponse() *ResponseWriter {
 	return &ResponseWriter{}
 }
 
+// NewResponseV2 returns a new response writer to capture
+// http output in the payload format 2.0 of HTTP APIs.
+func NewResponseV2() *ResponseWriter {
+	return &ResponseWriter{v2: true}
+}
+
+// NewResponseALB returns a new response writer to capture http output
+// for an Application Load Balancer. Header fields are single-valued
+// unless multi-value headers are enabled for the target group.
+func NewResponseALB(multiValue bool) *ResponseWriter {
+	return &ResponseWriter{alb: true, singleValue: !multiValue}
+}
+
 // Header implementation.
 func (w *ResponseWriter) Header() http.Header {
 	if w.header == nil {
@@ -56,22 +73,50 @@ func (w *ResponseWriter) WriteHeader(status int) {
 
 	w.out.StatusCode = status
 
-	h := make(map[string]string)
+	if w.alb {
+		w.out.StatusDescription = fmt.Sprintf("%d %s", status, http.StatusText(status))
+	}
+
+	if w.v2 {
+		w.out.Headers, w.out.Cookies = headersV2(w.Header())
+		w.wroteHeader = true
+		return
+	}
 
-	// API Gateway does not support multiple set-cookie fields
-	// so we have to stagger the casing in order to support this.
-	util.FixMultipleSetCookie(w.Header())
+	// without multi-value headers each Set-Cookie value is
+	// given a distinct casing so that none are overwritten
+	if w.singleValue {
+		header := w.Header().Clone()
+		util.FixMultipleSetCookie(header)
+
+		h := make(map[string]string)
+
+		for k, v := range header {
+			if len(v) > 0 {
+				h[k] = v[len(v)-1]
+			}
+		}
+
+		w.out.Headers = h
+		w.wroteHeader = true
+		return
+	}
+
+	h := make(map[string][]string)
 
 	for k, v := range w.Header() {
 		if len(v) > 0 {
-			h[k] = v[len(v)-1]
+			h[k] = v
 		}
 	}
 
-	w.out.Headers = h
+	w.out.MultiValueHeaders = h
 	w.wroteHeader = true
 }
 
+// Flush implementation, a no-op as the response is buffered.
+func (w *ResponseWriter) Flush() {}
+
 // End the request.
 func (w *ResponseWriter) End() Output {
 	w.out.IsBase64Encoded = isBinary(w.header)
@@ -85,6 +130,26 @@ func (w *ResponseWriter) End() Output {
 	return w.out
 }
 
+// headersV2 returns the header fields of the payload format 2.0, which
+// supports comma-separated values, and the Set-Cookie values as cookies.
+func headersV2(header http.Header) (map[string]string, []string) {
+	h := make(map[string]string)
+	var cookies []string
+
+	for k, v := range header {
+		if k == "Set-Cookie" {
+			cookies = append(cookies, v...)
+			continue
+		}
+
+		if len(v) > 0 {
+			h[k] = strings.Join(v, ", ")
+		}
+	}
+
+	return h, cookies
+}
+
 // isBinary returns true if the response reprensents binary.
 func isBinary(h http.Header) bool {
 	if !isTextMime(h.Get("Content-Type")) {
@@ -50,7 +50,7 @@ func TestResponseWriter_Write_text(t *testing.T) {
 			e := w.End()
 			assert.Equal(t, 200, e.StatusCode)
 			assert.Equal(t, "hello world\n", e.Body)
-			assert.Equal(t, kind, e.Headers["Content-Type"])
+			assert.Equal(t, []string{kind}, e.MultiValueHeaders["Content-Type"])
 			assert.False(t, e.IsBase64Encoded)
 		})
 	}
@@ -64,7 +64,7 @@ func TestResponseWriter_Write_binary(t *testing.T) {
 	e := w.End()
 	assert.Equal(t, 200, e.StatusCode)
 	assert.Equal(t, "ZGF0YQ==", e.Body)
-	assert.Equal(t, "image/png", e.Headers["Content-Type"])
+	assert.Equal(t, []string{"image/png"}, e.MultiValueHeaders["Content-Type"])
 	assert.True(t, e.IsBase64Encoded)
 }
 
@@ -77,7 +77,7 @@ func TestResponseWriter_Write_gzip(t *testing.T) {
 	e := w.End()
 	assert.Equal(t, 200, e.StatusCode)
 	assert.Equal(t, "ZGF0YQ==", e.Body)
-	assert.Equal(t, "text/plain", e.Headers["Content-Type"])
+	assert.Equal(t, []string{"text/plain"}, e.MultiValueHeaders["Content-Type"])
 	assert.True(t, e.IsBase64Encoded)
 }
 
@@ -89,5 +89,88 @@ func TestResponseWriter_WriteHeader(t *testing.T) {
 	e := w.End()
 	assert.Equal(t, 404, e.StatusCode)
 	assert.Equal(t, "Not Found\n", e.Body)
-	assert.Equal(t, "text/plain; charset=utf8", e.Headers["Content-Type"])
+	assert.Equal(t, []string{"text/plain; charset=utf8"}, e.MultiValueHeaders["Content-Type"])
+}
+
+func TestResponseWriter_multiValue(t *testing.T) {
+	w := NewResponse()
+	w.Header().Add("Set-Cookie", "session=abc")
+	w.Header().Add("Set-Cookie", "theme=dark")
+	w.Header().Add("Link", "</style.css>; rel=preload")
+	w.Header().Add("Link", "</app.js>; rel=preload")
+	w.Header().Set("Content-Type", "text/plain")
+	w.Write([]byte("Hello World"))
+
+	e := w.End()
+	assert.Equal(t, 200, e.StatusCode)
+	assert.Equal(t, "Hello World", e.Body)
+	assert.Empty(t, e.Headers)
+	assert.Equal(t, map[string][]string{
+		"Content-Type": {"text/plain"},
+		"Link":         {"</style.css>; rel=preload", "</app.js>; rel=preload"},
+		"Set-Cookie":   {"session=abc", "theme=dark"},
+	}, e.MultiValueHeaders)
+}
+
+func TestResponseWriter_alb(t *testing.T) {
+	t.Run("multi-value", func(t *testing.T) {
+		w := NewResponseALB(true)
+		w.Header().Add("Link", "</style.css>; rel=preload")
+		w.Header().Add("Link", "</app.js>; rel=preload")
+		w.WriteHeader(404)
+		w.Write([]byte("Not Found"))
+
+		e := w.End()
+		assert.Equal(t, 404, e.StatusCode)
+		assert.Equal(t, "404 Not Found", e.StatusDescription)
+		assert.Equal(t, []string{"</style.css>; rel=preload", "</app.js>; rel=preload"}, e.MultiValueHeaders["Link"])
+		assert.Empty(t, e.Headers)
+	})
+
+	t.Run("single-value", func(t *testing.T) {
+		w := NewResponseALB(false)
+		w.Header().Set("Content-Type", "text/plain")
+		w.Write([]byte("Hello World"))
+
+		e := w.End()
+		assert.Equal(t, 200, e.StatusCode)
+		assert.Equal(t, "200 OK", e.StatusDescription)
+		assert.Equal(t, map[string]string{"Content-Type": "text/plain"}, e.Headers)
+		assert.Empty(t, e.MultiValueHeaders)
+	})
+
+	t.Run("single-value cookies", func(t *testing.T) {
+		w := NewResponseALB(false)
+		w.Header().Set("Content-Type", "text/plain")
+		w.Header().Add("Set-Cookie", "session=abc")
+		w.Header().Add("Set-Cookie", "theme=dark")
+		w.Write([]byte("Hello World"))
+
+		e := w.End()
+		assert.Equal(t, map[string]string{
+			"Content-Type": "text/plain",
+			"set-cookie":   "session=abc",
+			"Set-cookie":   "theme=dark",
+		}, e.Headers)
+		assert.Equal(t, []string{"session=abc", "theme=dark"}, w.Header()["Set-Cookie"])
+	})
+}
+
+func TestResponseWriter_v2(t *testing.T) {
+	w := NewResponseV2()
+	w.Header().Add("Set-Cookie", "session=abc")
+	w.Header().Add("Set-Cookie", "theme=dark")
+	w.Header().Add("Vary", "Accept")
+	w.Header().Add("Vary", "Accept-Encoding")
+	w.Header().Set("Content-Type", "text/plain")
+	w.Write([]byte("Hello World"))
+
+	e := w.End()
+	assert.Equal(t, 200, e.StatusCode)
+	assert.Equal(t, "Hello World", e.Body)
+	assert.Equal(t, []string{"session=abc", "theme=dark"}, e.Cookies)
+	assert.Equal(t, map[string]string{
+		"Content-Type": "text/plain",
+		"Vary":         "Accept, Accept-Encoding",
+	}, e.Headers)
 }
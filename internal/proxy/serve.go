@@ -0,0 +1,107 @@
+package proxy
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/apex/log"
+	jsonlog "github.com/apex/log/handlers/json"
+	"github.com/pkg/errors"
+
+	"github.com/apex/up"
+	"github.com/apex/up/handler"
+	"github.com/apex/up/internal/logs"
+	"github.com/apex/up/internal/logs/writer"
+	"github.com/apex/up/internal/util"
+	"github.com/apex/up/platform/aws/runtime"
+)
+
+// Serve Lambda invocations with the handler returned by
+// newHandler, wrapped with all Up middleware.
+func Serve(newHandler func(*up.Config) (http.Handler, error)) {
+	start := time.Now()
+	stage := os.Getenv("UP_STAGE")
+
+	// setup logging
+	log.SetHandler(jsonlog.Default)
+	if s := os.Getenv("LOG_LEVEL"); s != "" {
+		log.SetLevelFromString(s)
+	}
+
+	log.Log = log.WithFields(logs.Fields())
+	log.Info("initializing")
+
+	// output of the app is logged, as stdout carries the responses
+	stdout, err := redirectStdout(writer.New(log.InfoLevel, log.Log))
+	if err != nil {
+		log.Fatalf("error redirecting stdout: %s", err)
+	}
+
+	// read config
+	c, err := readConfig()
+	if err != nil {
+		log.Fatalf("error reading config: %s", err)
+	}
+
+	ctx := log.WithFields(log.Fields{
+		"name": c.Name,
+		"type": c.Type,
+	})
+
+	// init project
+	p := runtime.New(c)
+
+	// init runtime
+	if err := p.Init(stage); err != nil {
+		ctx.Fatalf("error initializing: %s", err)
+	}
+
+	// overrides
+	if err := c.Override(stage); err != nil {
+		ctx.Fatalf("error overriding: %s", err)
+	}
+
+	// create handler
+	h, err := newHandler(c)
+	if err != nil {
+		ctx.Fatalf("error creating handler: %s", err)
+	}
+
+	// init handler
+	h, err = handler.New(c, h)
+	if err != nil {
+		ctx.Fatalf("error initializing handler: %s", err)
+	}
+
+	// serve
+	log.WithField("duration", util.MillisecondsSince(start)).Info("initialized")
+	timeout := time.Duration(c.Lambda.Timeout) * time.Second
+
+	if err := stream(os.Stdin, stdout, h, timeout, c.API.Type == "url"); err != nil {
+		ctx.Fatalf("error serving: %s", err)
+	}
+}
+
+// readConfig reads the config, resolving variables from
+// the values resolved when deploying, if present.
+func readConfig() (*up.Config, error) {
+	b, err := ioutil.ReadFile("_variables.json")
+
+	if os.IsNotExist(err) {
+		return up.ReadConfig(up.FindConfig())
+	}
+
+	if err != nil {
+		return nil, errors.Wrap(err, "reading variables")
+	}
+
+	var vars map[string]string
+	if err := json.Unmarshal(b, &vars); err != nil {
+		return nil, errors.Wrap(err, "parsing variables")
+	}
+
+	return up.ReadDeployedConfig(up.FindConfig(), vars)
+}
@@ -0,0 +1,38 @@
+package proxy
+
+import (
+	"io"
+	"os"
+	"syscall"
+
+	"github.com/pkg/errors"
+)
+
+// redirectStdout redirects the stdout of the process to w, so that output
+// of the app such as fmt.Println() does not corrupt the responses written
+// to the shim, returning a private file of the original stdout for them.
+func redirectStdout(w io.Writer) (*os.File, error) {
+	fd, err := syscall.Dup(syscall.Stdout)
+	if err != nil {
+		return nil, errors.Wrap(err, "duplicating stdout")
+	}
+
+	syscall.CloseOnExec(fd)
+	stdout := os.NewFile(uintptr(fd), "/dev/stdout")
+
+	r, pw, err := os.Pipe()
+	if err != nil {
+		stdout.Close()
+		return nil, errors.Wrap(err, "creating pipe")
+	}
+
+	if err := syscall.Dup3(int(pw.Fd()), syscall.Stdout, 0); err != nil {
+		stdout.Close()
+		return nil, errors.Wrap(err, "redirecting stdout")
+	}
+
+	pw.Close()
+	go io.Copy(w, r)
+
+	return stdout, nil
+}
@@ -0,0 +1,73 @@
+package proxy
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/tj/assert"
+
+	"github.com/apex/up"
+	"github.com/apex/up/handler"
+)
+
+// lines is a writer sending each write to the channel.
+type lines chan string
+
+// Write implementation.
+func (l lines) Write(b []byte) (int, error) {
+	l <- string(b)
+	return len(b), nil
+}
+
+func TestRedirectStdout(t *testing.T) {
+	f, err := ioutil.TempFile("", "up-stdout")
+	assert.NoError(t, err, "tempfile")
+	defer os.Remove(f.Name())
+
+	// stdout of the test is restored afterwards
+	saved, err := syscall.Dup(syscall.Stdout)
+	assert.NoError(t, err, "dup")
+	assert.NoError(t, syscall.Dup3(int(f.Fd()), syscall.Stdout, 0), "dup3")
+	defer func() {
+		syscall.Dup3(saved, syscall.Stdout, 0)
+		syscall.Close(saved)
+	}()
+
+	logs := make(lines, 10)
+	stdout, err := redirectStdout(logs)
+	assert.NoError(t, err, "redirect")
+
+	c := up.MustParseConfigString(`{ "name": "app", "type": "static" }`)
+	h, err := handler.New(c, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Println("hello from app")
+		fmt.Fprint(w, "Hello World")
+	}))
+	assert.NoError(t, err, "handler")
+
+	in := fmt.Sprintf(`{"id":"1","event":%s,"context":{}}`+"\n", getEventV2)
+	assert.NoError(t, stream(strings.NewReader(in), stdout, h, time.Second, false), "stream")
+
+	// output of the app is logged
+	select {
+	case s := <-logs:
+		assert.Equal(t, "hello from app\n", s)
+	case <-time.After(time.Second):
+		t.Fatal("timed out waiting for app output")
+	}
+
+	// responses are written to the original stdout
+	b, err := ioutil.ReadFile(f.Name())
+	assert.NoError(t, err, "read")
+
+	var out streamOutput
+	assert.NoError(t, json.Unmarshal(b, &out), "unmarshal")
+	assert.Equal(t, "1", out.ID)
+	assert.Equal(t, "Hello World", out.Value.(map[string]interface{})["body"])
+}
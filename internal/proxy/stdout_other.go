@@ -0,0 +1,14 @@
+//go:build !linux
+// +build !linux
+
+package proxy
+
+import (
+	"io"
+	"os"
+)
+
+// redirectStdout is a no-op returning stdout, as functions only run on Linux.
+func redirectStdout(w io.Writer) (*os.File, error) {
+	return os.Stdout, nil
+}
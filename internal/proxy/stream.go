@@ -0,0 +1,218 @@
+package proxy
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/apex/go-apex"
+	"github.com/pkg/errors"
+)
+
+// streamChunkSize is the size of buffered writes which are sent
+// to the shim as a chunk without waiting for a flush.
+const streamChunkSize = 32 << 10
+
+// streamInput is an invocation from the shim.
+type streamInput struct {
+	ID      string          `json:"id"`
+	Event   json.RawMessage `json:"event"`
+	Context *apex.Context   `json:"context"`
+
+	// Deadline of the invocation in milliseconds since the epoch.
+	Deadline int64 `json:"deadline"`
+}
+
+// deadline returns the deadline of the invocation, falling
+// back on the timeout when not passed by the shim.
+func (in *streamInput) deadline(timeout time.Duration) time.Time {
+	if in.Deadline == 0 {
+		return time.Now().Add(timeout)
+	}
+
+	return time.Unix(0, in.Deadline*int64(time.Millisecond))
+}
+
+// streamHead is the status and header fields of a streamed response.
+type streamHead struct {
+	StatusCode int               `json:"statusCode"`
+	Headers    map[string]string `json:"headers,omitempty"`
+	Cookies    []string          `json:"cookies,omitempty"`
+}
+
+// streamOutput is a message for the shim, the head and chunks of a
+// streamed response, followed by the result of the invocation.
+type streamOutput struct {
+	ID    string      `json:"id"`
+	Head  *streamHead `json:"head,omitempty"`
+	Chunk []byte      `json:"chunk,omitempty"`
+	Error string      `json:"error,omitempty"`
+	Value interface{} `json:"value,omitempty"`
+}
+
+// stream serves Lambda invocations from the shim like apex.Handle, reading
+// them from r and writing responses to w, with requests bound by the remaining
+// time of the invocation. When streaming is enabled the responses of Function
+// URLs are written incrementally in the response streaming invoke mode, other
+// events such as those of load balancers are buffered.
+func stream(r io.Reader, w io.Writer, h http.Handler, timeout time.Duration, streaming bool) error {
+	dec := json.NewDecoder(r)
+	enc := json.NewEncoder(w)
+
+	for {
+		var in streamInput
+		err := dec.Decode(&in)
+
+		if err == io.EOF {
+			return nil
+		}
+
+		if err != nil {
+			return errors.Wrap(err, "decoding input")
+		}
+
+		out := streamOutput{ID: in.ID}
+		deadline := in.deadline(timeout)
+
+		e := new(Input)
+		if err := json.Unmarshal(in.Event, e); err != nil {
+			out.Error = errors.Wrap(err, "parsing proxy event").Error()
+		} else if !streaming || !e.IsV2() {
+			v, err := handle(h, in.Event, deadline)
+			out.Value = v
+			if err != nil {
+				out.Error = err.Error()
+			}
+		} else if err := serveStream(h, e, NewStreamWriter(in.ID, enc), deadline); err != nil {
+			out.Error = err.Error()
+		}
+
+		if err := enc.Encode(out); err != nil {
+			return errors.Wrap(err, "encoding output")
+		}
+	}
+}
+
+// serveStream serves the event e, streaming the response to w.
+func serveStream(h http.Handler, e *Input, w *StreamWriter, deadline time.Time) error {
+	c, cancel := context.WithDeadline(context.Background(), deadline)
+	defer cancel()
+
+	req, err := NewRequest(e)
+	if err != nil {
+		return errors.Wrap(err, "creating new request from event")
+	}
+
+	h.ServeHTTP(w, req.WithContext(c))
+	return w.End()
+}
+
+// StreamWriter implements the http.ResponseWriter and http.Flusher
+// interfaces, streaming the response to the shim as it is written.
+type StreamWriter struct {
+	id          string
+	enc         *json.Encoder
+	buf         bytes.Buffer
+	header      http.Header
+	wroteHeader bool
+	err         error
+}
+
+// NewStreamWriter returns a new response writer streaming
+// the response of the invocation id to enc.
+func NewStreamWriter(id string, enc *json.Encoder) *StreamWriter {
+	return &StreamWriter{
+		id:  id,
+		enc: enc,
+	}
+}
+
+// Header implementation.
+func (w *StreamWriter) Header() http.Header {
+	if w.header == nil {
+		w.header = make(http.Header)
+	}
+
+	return w.header
+}
+
+// Write implementation.
+func (w *StreamWriter) Write(b []byte) (int, error) {
+	if !w.wroteHeader {
+		w.WriteHeader(http.StatusOK)
+	}
+
+	if w.err != nil {
+		return 0, w.err
+	}
+
+	w.buf.Write(b)
+
+	if w.buf.Len() >= streamChunkSize {
+		w.Flush()
+	}
+
+	return len(b), w.err
+}
+
+// WriteHeader implementation.
+func (w *StreamWriter) WriteHeader(status int) {
+	if w.wroteHeader {
+		return
+	}
+
+	if w.Header().Get("Content-Type") == "" {
+		w.Header().Set("Content-Type", "text/plain; charset=utf8")
+	}
+
+	headers, cookies := headersV2(w.Header())
+
+	w.send(streamOutput{
+		ID: w.id,
+		Head: &streamHead{
+			StatusCode: status,
+			Headers:    headers,
+			Cookies:    cookies,
+		},
+	})
+
+	w.wroteHeader = true
+}
+
+// Flush implementation.
+func (w *StreamWriter) Flush() {
+	if !w.wroteHeader {
+		w.WriteHeader(http.StatusOK)
+	}
+
+	if w.buf.Len() == 0 {
+		return
+	}
+
+	w.send(streamOutput{
+		ID:    w.id,
+		Chunk: w.buf.Bytes(),
+	})
+
+	w.buf.Reset()
+}
+
+// End the response, flushing buffered writes.
+func (w *StreamWriter) End() error {
+	w.Flush()
+	return w.err
+}
+
+// send the message unless a previous send failed.
+func (w *StreamWriter) send(out streamOutput) {
+	if w.err != nil {
+		return
+	}
+
+	if err := w.enc.Encode(out); err != nil {
+		w.err = errors.Wrap(err, "encoding output")
+	}
+}
@@ -0,0 +1,110 @@
+package proxy
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/tj/assert"
+)
+
+// invoke returns the output of streaming the given events.
+func invoke(t *testing.T, h http.Handler, events ...string) (out []streamOutput) {
+	var in bytes.Buffer
+	for i, e := range events {
+		fmt.Fprintf(&in, `{"id":"%d","event":%s,"context":{}}`+"\n", i+1, e)
+	}
+
+	var buf bytes.Buffer
+	assert.NoError(t, stream(&in, &buf, h, time.Second, true), "stream")
+
+	dec := json.NewDecoder(&buf)
+	for {
+		var v streamOutput
+		err := dec.Decode(&v)
+		if err == io.EOF {
+			return
+		}
+		assert.NoError(t, err, "decode")
+		out = append(out, v)
+	}
+}
+
+func TestStream(t *testing.T) {
+	h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.Header().Add("Set-Cookie", "session=abc")
+		fmt.Fprint(w, "data: Hello\n\n")
+		w.(http.Flusher).Flush()
+		fmt.Fprint(w, "data: World\n\n")
+	})
+
+	t.Run("function url", func(t *testing.T) {
+		out := invoke(t, h, getEventV2)
+		assert.Len(t, out, 4)
+
+		assert.Equal(t, "1", out[0].ID)
+		assert.Equal(t, &streamHead{
+			StatusCode: 200,
+			Headers:    map[string]string{"Content-Type": "text/event-stream"},
+			Cookies:    []string{"session=abc"},
+		}, out[0].Head)
+
+		assert.Equal(t, "data: Hello\n\n", string(out[1].Chunk))
+		assert.Equal(t, "data: World\n\n", string(out[2].Chunk))
+		assert.Equal(t, streamOutput{ID: "1"}, out[3])
+	})
+
+	t.Run("large writes", func(t *testing.T) {
+		h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte(strings.Repeat("a", streamChunkSize+1)))
+			w.Write([]byte("b"))
+		})
+
+		out := invoke(t, h, getEventV2)
+		assert.Len(t, out, 4)
+		assert.Len(t, out[1].Chunk, streamChunkSize+1)
+		assert.Equal(t, "b", string(out[2].Chunk))
+	})
+
+	t.Run("buffered", func(t *testing.T) {
+		out := invoke(t, h, getEventALB, getEventV2)
+		assert.Len(t, out, 5)
+
+		assert.Equal(t, "1", out[0].ID)
+		assert.Nil(t, out[0].Head)
+		v := out[0].Value.(map[string]interface{})
+		assert.Equal(t, "200 OK", v["statusDescription"])
+		assert.Equal(t, "data: Hello\n\ndata: World\n\n", v["body"])
+
+		assert.Equal(t, "2", out[1].ID)
+		assert.NotNil(t, out[1].Head)
+	})
+
+	t.Run("invalid event", func(t *testing.T) {
+		out := invoke(t, h, `"nope"`)
+		assert.Len(t, out, 1)
+		assert.Contains(t, out[0].Error, "parsing proxy event")
+	})
+}
+
+func TestStream_deadline(t *testing.T) {
+	deadline := time.Now().Add(3 * time.Second).Truncate(time.Millisecond)
+
+	var got time.Time
+	h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got, _ = r.Context().Deadline()
+	})
+
+	for _, streaming := range []bool{true, false} {
+		var in, out bytes.Buffer
+		fmt.Fprintf(&in, `{"id":"1","event":%s,"context":{},"deadline":%d}`+"\n", getEventV2, deadline.UnixNano()/int64(time.Millisecond))
+		assert.NoError(t, stream(&in, &out, h, time.Minute, streaming), "stream")
+		assert.True(t, deadline.Equal(got), "deadline")
+	}
+}
@@ -58,7 +58,6 @@ function handleLine(line) {
   }
 
   const c = callbacks.get(msg.id);
-  callbacks.delete(msg.id);
 
   if (!c) {
     if (debug) {
@@ -68,6 +67,13 @@ function handleLine(line) {
     return;
   }
 
+  // streamed response head and body chunks
+  if (msg.head || msg.chunk) {
+    c.write(msg);
+    return;
+  }
+
+  callbacks.delete(msg.id);
   c(msg.error, msg.value);
 }
 
@@ -142,17 +148,77 @@ out.on('readable', () => {
 });
 
 /**
- * Handle events.
+ * send an event to the child process, invoking cb with the response.
  */
-exports.handle = function(event, ctx, cb) {
-  ctx.callbackWaitsForEmptyEventLoop = false;
 
+function send(event, ctx, cb) {
   const id = nextId();
   callbacks.set(id, cb);
 
   proc.stdin.write(JSON.stringify({
     "id": id,
     "event": event,
-    "context": ctx
+    "context": ctx,
+    "deadline": Date.now() + ctx.getRemainingTimeInMillis()
   })+'\n');
 }
+
+/**
+ * Handle events.
+ */
+
+function handle(event, ctx, cb) {
+  ctx.callbackWaitsForEmptyEventLoop = false;
+  send(event, ctx, cb);
+}
+
+/**
+ * Handle events, streaming the response of function urls. Responses
+ * without a head, such as those of load balancers, are written as JSON.
+ */
+
+function handleStream(event, responseStream, ctx) {
+  return new Promise(function(resolve, reject){
+    let stream = null;
+
+    function done(err, value) {
+      if (err && !stream) {
+        reject(new Error(err));
+        return;
+      }
+
+      if (err) {
+        console.error('[shim] error: %s', err);
+      }
+
+      if (!stream) {
+        stream = responseStream;
+        if (value !== undefined) {
+          stream.write(JSON.stringify(value));
+        }
+      }
+
+      stream.end();
+      resolve();
+    }
+
+    done.write = function(msg){
+      if (msg.head) {
+        stream = awslambda.HttpResponseStream.from(responseStream, msg.head);
+        return;
+      }
+
+      stream.write(Buffer.from(msg.chunk, 'base64'));
+    };
+
+    send(event, ctx, done);
+  });
+}
+
+/**
+ * Response streaming is enabled for function urls.
+ */
+
+const streaming = process.env.UP_STREAM && typeof awslambda !== 'undefined';
+
+exports.handle = streaming ? awslambda.streamifyResponse(handleStream) : handle;
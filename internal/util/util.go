@@ -220,9 +220,15 @@ func WaitForListen(u *url.URL, timeout time.Duration) error {
 	}
 }
 
-// IsListening returns true if there's a server listening on `u`.
+// IsListening returns true if there's a server listening on `u`,
+// which may be a Unix domain socket using the "unix" scheme.
 func IsListening(u *url.URL) bool {
-	conn, err := net.Dial("tcp", u.Host)
+	network, addr := "tcp", u.Host
+	if u.Scheme == "unix" {
+		network, addr = "unix", u.Path
+	}
+
+	conn, err := net.Dial(network, addr)
 	if err != nil {
 		return false
 	}
@@ -86,3 +86,12 @@ func Lists(vals, list []string) error {
 
 	return nil
 }
+
+// Range validation.
+func Range(n, min, max int) error {
+	if n < min || n > max {
+		return errors.Errorf("%d is invalid, must be between %d and %d", n, min, max)
+	}
+
+	return nil
+}
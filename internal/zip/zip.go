@@ -37,7 +37,7 @@ func Build(dir string) (io.ReadCloser, *archive.Stats, error) {
 		strings.NewReader(".*\n"),
 		strings.NewReader("\n!vendor\n!node_modules/**\n!.pypath/**\n"),
 		upignore,
-		strings.NewReader("\n!main\n!server\n!_proxy.js\n!up.json\n!pom.xml\n!build.gradle\n!project.clj\ngin-bin\nup\n"))
+		strings.NewReader("\n!main\n!server\n!_proxy.js\n!_variables.json\n!up.json\n!up.yml\n!up.yaml\n!up.toml\n!pom.xml\n!build.gradle\n!project.clj\n!package.json\n!tsconfig.json\n!app.py\n!Gemfile\n!Gemfile.lock\n!config.ru\n!Cargo.toml\n!composer.json\n!deno.json\n!deno.jsonc\n!*.csproj\n!*.fsproj\ngin-bin\nup\n"))
 
 	filter, err := archive.FilterPatterns(r)
 	if err != nil {
@@ -14,10 +14,10 @@ type Domains struct {
 	client *r.Route53Domains
 }
 
-// New returns a new domain manager.
-func New() *Domains {
+// New returns a new domain manager using the given session.
+func New(s *session.Session) *Domains {
 	return &Domains{
-		client: r.New(session.New(aws.NewConfig().WithRegion("us-east-1"))),
+		client: r.New(s),
 	}
 }
 
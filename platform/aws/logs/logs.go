@@ -12,8 +12,6 @@ import (
 	jsonlog "github.com/apex/log/handlers/json"
 	"github.com/apex/up"
 
-	"github.com/aws/aws-sdk-go/aws"
-	"github.com/aws/aws-sdk-go/aws/session"
 	"github.com/aws/aws-sdk-go/service/cloudwatchlogs"
 	"github.com/tj/aws/logs"
 
@@ -25,14 +23,15 @@ import (
 // Logs implementation.
 type Logs struct {
 	up.LogsConfig
-	group string
-	query string
-	w     io.WriteCloser
+	client *cloudwatchlogs.CloudWatchLogs
+	group  string
+	query  string
+	w      io.WriteCloser
 	io.Reader
 }
 
-// New log tailer.
-func New(group string, c up.LogsConfig) up.Logs {
+// New log tailer for the group, using the given client.
+func New(group string, c up.LogsConfig, client *cloudwatchlogs.CloudWatchLogs) up.Logs {
 	r, w := io.Pipe()
 
 	query, err := parseQuery(c.Query)
@@ -43,6 +42,7 @@ func New(group string, c up.LogsConfig) up.Logs {
 
 	l := &Logs{
 		LogsConfig: c,
+		client:     client,
 		query:      query,
 		group:      group,
 		Reader:     r,
@@ -57,7 +57,7 @@ func New(group string, c up.LogsConfig) up.Logs {
 // start fetching logs.
 func (l *Logs) start() {
 	tailer := logs.New(logs.Config{
-		Service:       cloudwatchlogs.New(session.New(aws.NewConfig().WithRegion(l.Region))),
+		Service:       l.client,
 		StartTime:     l.Since,
 		PollInterval:  2 * time.Second,
 		Follow:        l.Follow,
@@ -8,6 +8,7 @@ import (
 	"io"
 	"io/ioutil"
 	"os"
+	"sort"
 	"strings"
 	"sync"
 	"time"
@@ -15,9 +16,11 @@ import (
 	"github.com/apex/log"
 	"github.com/apex/log/handlers/discard"
 	"github.com/aws/aws-sdk-go/aws"
-	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/aws/awserr"
 	"github.com/aws/aws-sdk-go/service/acm"
 	"github.com/aws/aws-sdk-go/service/apigateway"
+	"github.com/aws/aws-sdk-go/service/apigatewayv2"
+	"github.com/aws/aws-sdk-go/service/cloudwatchlogs"
 	"github.com/aws/aws-sdk-go/service/iam"
 	"github.com/aws/aws-sdk-go/service/lambda"
 	"github.com/aws/aws-sdk-go/service/route53"
@@ -147,73 +150,147 @@ func (p *Platform) Init(stage string) error {
 
 // Deploy implementation.
 func (p *Platform) Deploy(d up.Deploy) error {
-	regions := p.config.Regions
-	var g errgroup.Group
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	created := make(map[string]string)
+	errs := make(regionErrors)
 
 	if err := p.createRole(); err != nil {
 		return errors.Wrap(err, "iam")
 	}
 
-	for _, r := range regions {
+	for _, r := range p.config.Regions {
 		region := r
-		g.Go(func() error {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
 			version, err := p.deploy(region, d)
-			if err == nil {
-				goto endpoint
-			}
 
-			if err != errFirstDeploy {
-				return errors.Wrap(err, region)
+			mu.Lock()
+			defer mu.Unlock()
+
+			switch err {
+			case nil:
+			case errFirstDeploy:
+				created[region] = version
+			default:
+				errs[region] = err
 			}
+		}()
+	}
+
+	wg.Wait()
 
+	// stacks are created one region at a time, as secondary
+	// regions rely on the hosted zones of the primary region
+	for _, region := range p.config.Regions {
+		if errs[region] != nil {
+			continue
+		}
+
+		if version, ok := created[region]; ok {
 			if err := p.CreateStack(region, version); err != nil {
-				return errors.Wrap(err, region)
+				errs[region] = errors.Wrap(err, "creating stack")
+				continue
 			}
 
-		endpoint:
-			url, err := p.URL(region, d.Stage)
-			if err != nil {
-				return errors.Wrap(err, "fetching url")
+			// the stage alias is created by the stack on first deploy
+			c := lambda.New(p.config.Session(region))
+			if err := p.provisionConcurrency(c, d.Stage); err != nil {
+				errs[region] = errors.Wrapf(err, "provisioning function stage %q alias", d.Stage)
+				continue
 			}
+		}
 
-			p.events.Emit("platform.deploy.url", event.Fields{
-				"url": url,
-			})
+		url, err := p.URL(region, d.Stage)
+		if err != nil {
+			errs[region] = errors.Wrap(err, "fetching url")
+			continue
+		}
 
-			return nil
+		p.events.Emit("platform.deploy.url", event.Fields{
+			"region": region,
+			"url":    url,
 		})
 	}
 
-	return g.Wait()
+	for _, region := range p.config.Regions {
+		if err := errs[region]; err != nil {
+			p.events.Emit("platform.deploy.error", event.Fields{
+				"region": region,
+				"error":  err.Error(),
+			})
+		}
+	}
+
+	if len(errs) > 0 {
+		return errs
+	}
+
+	return nil
 }
 
 // Logs implementation.
 func (p *Platform) Logs(c up.LogsConfig) up.Logs {
 	g := "/aws/lambda/" + p.config.Name
-	return logs.New(g, c)
+	s := p.config.Session(c.Region)
+	return logs.New(g, c, cloudwatchlogs.New(s))
 }
 
 // Domains implementation.
 func (p *Platform) Domains() up.Domains {
-	return domains.New()
+	return domains.New(p.config.Session("us-east-1"))
 }
 
 // URL returns the stage url.
 func (p *Platform) URL(region, stage string) (string, error) {
-	s := session.New(aws.NewConfig().WithRegion(region))
-	c := apigateway.New(s)
+	s := p.config.Session(region)
 
-	api, err := p.getAPI(c)
-	if err != nil {
-		return "", errors.Wrap(err, "fetching api")
+	var id *string
+
+	if p.config.API.Type == "url" {
+		res, err := lambda.New(s).GetFunctionUrlConfig(&lambda.GetFunctionUrlConfigInput{
+			FunctionName: &p.config.Name,
+			Qualifier:    &stage,
+		})
+
+		if util.IsNotFound(err) {
+			return "", errors.Errorf("cannot find the function URL, looks like you haven't deployed")
+		}
+
+		if err != nil {
+			return "", errors.Wrap(err, "fetching function url")
+		}
+
+		return *res.FunctionUrl, nil
 	}
 
-	if api == nil {
+	if p.config.API.Type == "http" {
+		api, err := p.getHTTPAPI(apigatewayv2.New(s))
+		if err != nil {
+			return "", errors.Wrap(err, "fetching api")
+		}
+
+		if api != nil {
+			id = api.ApiId
+		}
+	} else {
+		api, err := p.getAPI(apigateway.New(s))
+		if err != nil {
+			return "", errors.Wrap(err, "fetching api")
+		}
+
+		if api != nil {
+			id = api.Id
+		}
+	}
+
+	if id == nil {
 		return "", errors.Errorf("cannot find the API, looks like you haven't deployed")
 	}
 
-	id := fmt.Sprintf("https://%s.execute-api.%s.amazonaws.com/%s/", *api.Id, region, stage)
-	return id, nil
+	url := fmt.Sprintf("https://%s.execute-api.%s.amazonaws.com/%s/", *id, region, stage)
+	return url, nil
 }
 
 // CreateStack implementation.
@@ -224,7 +301,7 @@ func (p *Platform) CreateStack(region, version string) error {
 		versions[s.Name] = version
 	}
 
-	if err := p.createCerts(); err != nil {
+	if err := p.createCerts(region); err != nil {
 		return errors.Wrap(err, "creating certs")
 	}
 
@@ -283,7 +360,7 @@ func (p *Platform) PlanStack(region string) error {
 		return errors.Wrap(err, "fetching alias versions")
 	}
 
-	if err := p.createCerts(); err != nil {
+	if err := p.createCerts(region); err != nil {
 		return errors.Wrap(err, "creating certs")
 	}
 
@@ -297,7 +374,7 @@ func (p *Platform) PlanStack(region string) error {
 
 // ApplyStack implementation.
 func (p *Platform) ApplyStack(region string) error {
-	if err := p.createCerts(); err != nil {
+	if err := p.createCerts(region); err != nil {
 		return errors.Wrap(err, "creating certs")
 	}
 
@@ -307,7 +384,7 @@ func (p *Platform) ApplyStack(region string) error {
 // Exists implementation.
 func (p *Platform) Exists(region string) (bool, error) {
 	log.Debug("checking if application exists")
-	c := lambda.New(session.New(aws.NewConfig().WithRegion(region)))
+	c := lambda.New(p.config.Session(region))
 
 	_, err := c.GetFunctionConfiguration(&lambda.GetFunctionConfigurationInput{
 		FunctionName: &p.config.Name,
@@ -329,7 +406,7 @@ func (p *Platform) getAliasVersions(region string) (resources.Versions, error) {
 	var g errgroup.Group
 	var mu sync.Mutex
 
-	c := lambda.New(session.New(aws.NewConfig().WithRegion(region)))
+	c := lambda.New(p.config.Session(region))
 	versions := make(resources.Versions)
 
 	log.Debug("fetching aliases")
@@ -380,7 +457,7 @@ func (p *Platform) getAliasVersion(c *lambda.Lambda, stage string) (string, erro
 
 // getHostedZone returns existing hosted zones.
 func (p *Platform) getHostedZone() (zones []*route53.HostedZone, err error) {
-	r := route53.New(session.New(aws.NewConfig()))
+	r := route53.New(p.config.Session(""))
 
 	log.Debug("fetching hosted zones")
 	res, err := r.ListHostedZonesByName(&route53.ListHostedZonesByNameInput{
@@ -399,10 +476,17 @@ func (p *Platform) getHostedZone() (zones []*route53.HostedZone, err error) {
 //
 // We perform this task outside of CloudFormation because
 // the certificates currently must be created in the us-east-1
-// region. This also gives us a chance to let the user know
-// that they have to confirm an email.
-func (p *Platform) createCerts() error {
-	s := session.New(aws.NewConfig().WithRegion("us-east-1"))
+// region for edge endpoints, or in the stack's region for the
+// regional endpoints used when deploying to multiple regions,
+// and by HTTP APIs.
+// This also gives us a chance to let the user know that they
+// have to confirm an email.
+func (p *Platform) createCerts(region string) error {
+	if !p.config.IsMultiRegion() && p.config.API.Type != "http" {
+		region = "us-east-1"
+	}
+
+	s := p.config.Session(region)
 	a := acm.New(s)
 	var domains []string
 
@@ -502,7 +586,7 @@ func (p *Platform) deploy(region string, d up.Deploy) (version string, err error
 	}()
 
 	ctx := log.WithField("region", region)
-	s := session.New(aws.NewConfig().WithRegion(region))
+	s := p.config.Session(region)
 	u := s3manager.NewUploaderWithClient(s3.New(s))
 	a := apigateway.New(s)
 	c := lambda.New(s)
@@ -570,7 +654,12 @@ retry:
 			S3Bucket: b,
 			S3Key:    k,
 		},
-		VpcConfig: p.vpc(),
+		VpcConfig:        p.vpc(),
+		Architectures:    aws.StringSlice([]string{p.config.Lambda.Architecture}),
+		Layers:           aws.StringSlice(p.config.Lambda.Layers),
+		EphemeralStorage: p.ephemeralStorage(),
+		TracingConfig:    p.tracing(),
+		DeadLetterConfig: p.deadLetter(),
 	})
 
 	// IAM is eventually consistent apparently, so we have to keep retrying
@@ -584,6 +673,10 @@ retry:
 		return "", errors.Wrap(err, "creating function")
 	}
 
+	if err := p.reserveConcurrency(c); err != nil {
+		return "", errors.Wrap(err, "reserving concurrency")
+	}
+
 	return *res.Version, errFirstDeploy
 }
 
@@ -625,30 +718,39 @@ func (p *Platform) updateFunction(c *lambda.Lambda, a *apigateway.APIGateway, up
 		return "", err
 	}
 	_, err = c.UpdateFunctionConfiguration(&lambda.UpdateFunctionConfigurationInput{
-		FunctionName: &p.config.Name,
-		Handler:      &p.handler,
-		Runtime:      &p.config.Lambda.Runtime,
-		Role:         &p.config.Lambda.Role,
-		MemorySize:   aws.Int64(int64(p.config.Lambda.Memory)),
-		Timeout:      aws.Int64(int64(p.config.Lambda.Timeout)),
-		Environment:  env,
-		VpcConfig:    p.vpc(),
+		FunctionName:     &p.config.Name,
+		Handler:          &p.handler,
+		Runtime:          &p.config.Lambda.Runtime,
+		Role:             &p.config.Lambda.Role,
+		MemorySize:       aws.Int64(int64(p.config.Lambda.Memory)),
+		Timeout:          aws.Int64(int64(p.config.Lambda.Timeout)),
+		Environment:      env,
+		VpcConfig:        p.vpc(),
+		Layers:           aws.StringSlice(p.config.Lambda.Layers),
+		EphemeralStorage: p.ephemeralStorage(),
+		TracingConfig:    p.tracing(),
+		DeadLetterConfig: p.deadLetter(),
 	})
 
 	if err != nil {
 		return "", errors.Wrap(err, "updating function config")
 	}
 
+	if err := p.reserveConcurrency(c); err != nil {
+		return "", errors.Wrap(err, "reserving concurrency")
+	}
+
 	// update function code
 	log.Debug("updating function code")
 	if err := p.isPending(c); err != nil {
 		return "", err
 	}
 	res, err := c.UpdateFunctionCode(&lambda.UpdateFunctionCodeInput{
-		FunctionName: &p.config.Name,
-		Publish:      aws.Bool(true),
-		S3Bucket:     b,
-		S3Key:        k,
+		FunctionName:  &p.config.Name,
+		Publish:       aws.Bool(true),
+		S3Bucket:      b,
+		S3Key:         k,
+		Architectures: aws.StringSlice([]string{p.config.Lambda.Architecture}),
 	})
 
 	if err != nil {
@@ -660,6 +762,11 @@ func (p *Platform) updateFunction(c *lambda.Lambda, a *apigateway.APIGateway, up
 		return "", errors.Wrapf(err, "creating function stage %q alias", d.Stage)
 	}
 
+	// provision stage alias
+	if err := p.provisionConcurrency(c, d.Stage); err != nil {
+		return "", errors.Wrapf(err, "provisioning function stage %q alias", d.Stage)
+	}
+
 	// create git alias
 	if d.Commit != "" {
 		if err := p.alias(c, util.EncodeAlias(d.Commit), *res.Version); err != nil {
@@ -715,6 +822,82 @@ func (p *Platform) vpc() *lambda.VpcConfig {
 	}
 }
 
+// ephemeralStorage returns the /tmp storage configuration.
+func (p *Platform) ephemeralStorage() *lambda.EphemeralStorage {
+	return &lambda.EphemeralStorage{
+		Size: aws.Int64(int64(p.config.Lambda.EphemeralStorage)),
+	}
+}
+
+// tracing returns the tracing configuration.
+func (p *Platform) tracing() *lambda.TracingConfig {
+	return &lambda.TracingConfig{
+		Mode: &p.config.Lambda.Tracing,
+	}
+}
+
+// deadLetter returns the dead-letter configuration, an
+// empty target removes it from an existing function.
+func (p *Platform) deadLetter() *lambda.DeadLetterConfig {
+	return &lambda.DeadLetterConfig{
+		TargetArn: &p.config.Lambda.DeadLetterARN,
+	}
+}
+
+// reserveConcurrency reserves or removes the function's concurrency.
+func (p *Platform) reserveConcurrency(c *lambda.Lambda) error {
+	n := p.config.Lambda.ReservedConcurrency
+
+	if n == 0 {
+		log.Debug("removing reserved concurrency")
+		_, err := c.DeleteFunctionConcurrency(&lambda.DeleteFunctionConcurrencyInput{
+			FunctionName: &p.config.Name,
+		})
+
+		if util.IsNotFound(err) {
+			return nil
+		}
+
+		return err
+	}
+
+	log.Debugf("reserving concurrency of %d", n)
+	_, err := c.PutFunctionConcurrency(&lambda.PutFunctionConcurrencyInput{
+		FunctionName:                 &p.config.Name,
+		ReservedConcurrentExecutions: aws.Int64(int64(n)),
+	})
+
+	return err
+}
+
+// provisionConcurrency provisions or removes the concurrency of the stage alias.
+func (p *Platform) provisionConcurrency(c *lambda.Lambda, stage string) error {
+	n := p.config.Lambda.ProvisionedConcurrency
+
+	if n == 0 {
+		log.Debugf("removing provisioned concurrency of %s", stage)
+		_, err := c.DeleteProvisionedConcurrencyConfig(&lambda.DeleteProvisionedConcurrencyConfigInput{
+			FunctionName: &p.config.Name,
+			Qualifier:    &stage,
+		})
+
+		if isProvisionedConcurrencyNotFound(err) || util.IsNotFound(err) {
+			return nil
+		}
+
+		return err
+	}
+
+	log.Debugf("provisioning concurrency of %d for %s", n, stage)
+	_, err := c.PutProvisionedConcurrencyConfig(&lambda.PutProvisionedConcurrencyConfigInput{
+		FunctionName:                    &p.config.Name,
+		Qualifier:                       &stage,
+		ProvisionedConcurrentExecutions: aws.Int64(int64(n)),
+	})
+
+	return err
+}
+
 // alias creates or updates an alias.
 func (p *Platform) alias(c *lambda.Lambda, alias, version string) error {
 	log.Debugf("alias %s to %s", alias, version)
@@ -740,7 +923,7 @@ func (p *Platform) alias(c *lambda.Lambda, alias, version string) error {
 // deleteFunction deletes the lambda function.
 func (p *Platform) deleteFunction(region string) error {
 	// TODO: sessions all over... refactor
-	c := lambda.New(session.New(aws.NewConfig().WithRegion(region)))
+	c := lambda.New(p.config.Session(region))
 
 	_, err := c.DeleteFunction(&lambda.DeleteFunctionInput{
 		FunctionName: &p.config.Name,
@@ -755,6 +938,12 @@ func (p *Platform) loadEnvironment(d up.Deploy) (*lambda.Environment, error) {
 	m["UP_STAGE"] = &d.Stage
 	m["UP_COMMIT"] = &d.Commit
 	m["UP_AUTHOR"] = &d.Author
+
+	// the shim streams responses of function urls
+	if p.config.API.Type == "url" {
+		m["UP_STREAM"] = aws.String("1")
+	}
+
 	return &lambda.Environment{
 		Variables: m,
 	}, nil
@@ -762,7 +951,7 @@ func (p *Platform) loadEnvironment(d up.Deploy) (*lambda.Environment, error) {
 
 // createRole creates the IAM role unless it is present.
 func (p *Platform) createRole() error {
-	s := session.New(aws.NewConfig())
+	s := p.config.Session("")
 	c := iam.New(s)
 
 	name := p.roleName()
@@ -849,7 +1038,7 @@ func (p *Platform) roleName() string {
 // deleteRole deletes the role and policy.
 func (p *Platform) deleteRole(region string) error {
 	name := fmt.Sprintf("%s-function", p.config.Name)
-	c := iam.New(session.New(aws.NewConfig().WithRegion(region)))
+	c := iam.New(p.config.Session(region))
 
 	// role is provided
 	if s := p.config.Lambda.Role; s != "" {
@@ -879,7 +1068,7 @@ func (p *Platform) deleteRole(region string) error {
 
 // createBucket creates the bucket.
 func (p *Platform) createBucket(region string) error {
-	s := s3.New(session.New(aws.NewConfig().WithRegion(region)))
+	s := s3.New(p.config.Session(region))
 	n := p.getS3BucketName(region)
 
 	log.WithField("name", n).Debug("creating s3 bucket")
@@ -892,7 +1081,7 @@ func (p *Platform) createBucket(region string) error {
 
 // deleteBucketObjects deletes the objects for the app.
 func (p *Platform) deleteBucketObjects(region string) error {
-	s := s3.New(session.New(aws.NewConfig().WithRegion(region)))
+	s := s3.New(p.config.Session(region))
 	b := aws.String(p.getS3BucketName(region))
 	prefix := p.config.Name + "/"
 
@@ -941,26 +1130,70 @@ func (p *Platform) getAPI(c *apigateway.APIGateway) (api *apigateway.RestApi, er
 	return
 }
 
-// injectProxy injects the Go proxy.
+// getHTTPAPI returns the HTTP API if present or nil.
+func (p *Platform) getHTTPAPI(c *apigatewayv2.ApiGatewayV2) (api *apigatewayv2.Api, err error) {
+	name := p.config.Name
+
+	res, err := c.GetApis(&apigatewayv2.GetApisInput{
+		MaxResults: aws.String("500"),
+	})
+
+	if err != nil {
+		return nil, errors.Wrap(err, "fetching apis")
+	}
+
+	for _, a := range res.Items {
+		if *a.Name == name {
+			api = a
+		}
+	}
+
+	return
+}
+
+// injectProxy injects the Go proxy. Apps of type "go" are
+// built as the binary, serving their handler in-process.
 func (p *Platform) injectProxy() error {
 	log.Debugf("injecting proxy")
 
-	if err := ioutil.WriteFile("main", bin.MustAsset("up-proxy"), 0777); err != nil {
-		return errors.Wrap(err, "writing up-proxy")
+	if p.config.Type == "go" {
+		if !util.Exists("main") {
+			return errors.New(`type "go" requires the build hook to output the ./main binary`)
+		}
+	} else {
+		asset := "up-proxy-" + p.config.Lambda.Architecture
+		if err := ioutil.WriteFile("main", bin.MustAsset(asset), 0777); err != nil {
+			return errors.Wrap(err, "writing up-proxy")
+		}
 	}
 
 	if err := ioutil.WriteFile("_proxy.js", shim.MustAsset("index.js"), 0755); err != nil {
 		return errors.Wrap(err, "writing _proxy.js")
 	}
 
+	// variables are resolved here as their sources, such as the
+	// environment or git, are not available to the deployed function,
+	// secret ssm parameters are excluded and fetched by the function.
+	b, err := json.Marshal(p.config.Variables())
+	if err != nil {
+		return errors.Wrap(err, "marshaling variables")
+	}
+
+	if err := ioutil.WriteFile("_variables.json", b, 0644); err != nil {
+		return errors.Wrap(err, "writing _variables.json")
+	}
+
 	return nil
 }
 
 // removeProxy removes the Go proxy.
 func (p *Platform) removeProxy() error {
 	log.Debugf("removing proxy")
-	os.Remove("main")
+	if p.config.Type != "go" {
+		os.Remove("main")
+	}
 	os.Remove("_proxy.js")
+	os.Remove("_variables.json")
 	return nil
 }
 
@@ -1000,6 +1233,33 @@ func (p *Platform) functionPolicy() (string, error) {
 	return string(b), nil
 }
 
+// regionErrors is a map of region to deployment error.
+type regionErrors map[string]error
+
+// Error implementation.
+func (e regionErrors) Error() string {
+	var regions []string
+
+	for region := range e {
+		regions = append(regions, region)
+	}
+
+	sort.Strings(regions)
+
+	var s []string
+	for _, region := range regions {
+		s = append(s, fmt.Sprintf("%s: %s", region, e[region]))
+	}
+
+	return strings.Join(s, "; ")
+}
+
+// isProvisionedConcurrencyNotFound returns true if the alias has no provisioned concurrency.
+func isProvisionedConcurrencyNotFound(err error) bool {
+	e, ok := err.(awserr.Error)
+	return ok && e.Code() == lambda.ErrCodeProvisionedConcurrencyConfigNotFoundException
+}
+
 // isCreatingRole returns true if the role has not been created.
 func isCreatingRole(err error) bool {
 	return err != nil && strings.Contains(err.Error(), "role defined for the function cannot be assumed by Lambda")
@@ -5,6 +5,7 @@ import (
 
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/service/acm"
+	"github.com/pkg/errors"
 	"github.com/tj/assert"
 	
 	"github.com/apex/up/config"
@@ -84,3 +85,12 @@ func TestDeleteRole(t *testing.T) {
 		assert.NoError(t, p.deleteRole("us-west-2"), "deleteRole")
 	})
 }
+
+func TestRegionErrors(t *testing.T) {
+	errs := regionErrors{
+		"us-west-2": errors.New("boom"),
+		"eu-west-1": errors.New("timeout"),
+	}
+
+	assert.EqualError(t, errs, `eu-west-1: timeout; us-west-2: boom`)
+}
@@ -3,8 +3,6 @@ package lambda
 import (
 	"time"
 
-	"github.com/aws/aws-sdk-go/aws"
-	"github.com/aws/aws-sdk-go/aws/session"
 	"github.com/aws/aws-sdk-go/service/cloudwatch"
 	"github.com/golang/sync/errgroup"
 
@@ -59,7 +57,7 @@ var stats = []*stat{
 
 // ShowMetrics implementation.
 func (p *Platform) ShowMetrics(region, stage string, start time.Time) error {
-	s := session.New(aws.NewConfig().WithRegion(region))
+	s := p.config.Session(region)
 	c := cloudwatch.New(s)
 	var g errgroup.Group
 	name := p.config.Name
@@ -7,7 +7,6 @@ import (
 	"github.com/apex/log"
 	"github.com/apex/up/platform/event"
 	"github.com/aws/aws-sdk-go/aws"
-	"github.com/aws/aws-sdk-go/aws/session"
 	"github.com/aws/aws-sdk-go/service/s3"
 	"github.com/pkg/errors"
 )
@@ -20,7 +19,7 @@ func (p *Platform) Prune(region, stage string, versions int) error {
 		return errors.Wrap(err, "creating iam role")
 	}
 
-	s := s3.New(session.New(aws.NewConfig().WithRegion(region)))
+	s := s3.New(p.config.Session(region))
 	b := aws.String(p.getS3BucketName(region))
 	prefix := p.config.Name + "/" + stage + "/"
 
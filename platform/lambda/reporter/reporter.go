@@ -6,17 +6,26 @@ import "strings"
 
 // types map.
 var types = map[string]string{
-	"AWS::CloudFormation::Stack":       "Stack",
-	"AWS::Lambda::Alias":               "Lambda alias",
-	"AWS::Lambda::Permission":          "Lambda permission",
-	"AWS::ApiGateway::RestApi":         "API",
-	"AWS::ApiGateway::Method":          "API method",
-	"AWS::ApiGateway::Deployment":      "API deployment",
-	"AWS::ApiGateway::Resource":        "API resource",
-	"AWS::ApiGateway::DomainName":      "API domain",
-	"AWS::ApiGateway::BasePathMapping": "API mapping",
-	"AWS::Route53::HostedZone":         "DNS zone",
-	"AWS::Route53::RecordSet":          "DNS record",
+	"AWS::CloudFormation::Stack":                "Stack",
+	"AWS::Lambda::Alias":                        "Lambda alias",
+	"AWS::Lambda::Permission":                   "Lambda permission",
+	"AWS::ApiGateway::RestApi":                  "API",
+	"AWS::ApiGateway::Method":                   "API method",
+	"AWS::ApiGateway::Deployment":               "API deployment",
+	"AWS::ApiGateway::Resource":                 "API resource",
+	"AWS::ApiGateway::DomainName":               "API domain",
+	"AWS::ApiGateway::BasePathMapping":          "API mapping",
+	"AWS::ApiGatewayV2::Api":                    "API",
+	"AWS::ApiGatewayV2::Integration":            "API integration",
+	"AWS::ApiGatewayV2::Route":                  "API route",
+	"AWS::ApiGatewayV2::Stage":                  "API stage",
+	"AWS::ApiGatewayV2::DomainName":             "API domain",
+	"AWS::ApiGatewayV2::ApiMapping":             "API mapping",
+	"AWS::ElasticLoadBalancingV2::TargetGroup":  "ALB target group",
+	"AWS::ElasticLoadBalancingV2::ListenerRule": "ALB listener rule",
+	"AWS::Events::Rule":                         "Cron rule",
+	"AWS::Route53::HostedZone":                  "DNS zone",
+	"AWS::Route53::RecordSet":                   "DNS record",
 }
 
 // ResourceType returns a human-friendly resource type name.
@@ -1,12 +1,14 @@
 package resources
 
 import (
+	"encoding/json"
 	"fmt"
 	"strconv"
 
 	"github.com/apex/up"
 	"github.com/apex/up/config"
 	"github.com/apex/up/internal/util"
+	"github.com/aws/aws-sdk-go/aws/arn"
 	"github.com/aws/aws-sdk-go/service/route53"
 )
 
@@ -28,6 +30,11 @@ type Config struct {
 	// function aliases when updating a stack.
 	Versions Versions
 
+	// Region of the stack. When multiple regions are
+	// configured the first one is considered the primary,
+	// owning global resources such as hosted zones.
+	Region string
+
 	*up.Config
 }
 
@@ -83,6 +90,16 @@ func lambdaArnQualifier(name, qualifier string) Map {
 	return join(":", "arn", "aws", "lambda", ref("AWS::Region"), ref("AWS::AccountId"), "function", join(":", ref(name), qualifier))
 }
 
+// isMultiRegion returns true if the app is deployed to more than one region.
+func isMultiRegion(c *Config) bool {
+	return c.IsMultiRegion()
+}
+
+// isPrimaryRegion returns true if the stack is in the primary region.
+func isPrimaryRegion(c *Config) bool {
+	return !isMultiRegion(c) || c.Region == c.ConfiguredRegions()[0]
+}
+
 // getZone returns a zone by domain or nil.
 func getZone(c *Config, domain string) *route53.HostedZone {
 	for _, z := range c.Zones {
@@ -120,8 +137,31 @@ func dnsZone(c *Config, m Map, domain string) interface{} {
 	return ref(id)
 }
 
+// isHTTPAPI returns true if the API is an API Gateway HTTP API.
+func isHTTPAPI(c *Config) bool {
+	return c.API.Type == "http"
+}
+
+// isFunctionURL returns true if the app is served by Lambda Function URLs.
+func isFunctionURL(c *Config) bool {
+	return c.API.Type == "url"
+}
+
 // api sets up the app resources.
 func api(c *Config, m Map) {
+	switch {
+	case isFunctionURL(c):
+	case isHTTPAPI(c):
+		httpAPI(c, m)
+	default:
+		restAPI(c, m)
+	}
+
+	stages(c, m)
+}
+
+// restAPI sets up the REST API resources.
+func restAPI(c *Config, m Map) {
 	m["Api"] = Map{
 		"Type": "AWS::ApiGateway::RestApi",
 		"Properties": Map{
@@ -174,8 +214,37 @@ func api(c *Config, m Map) {
 			"Integration":       integration,
 		},
 	}
+}
 
-	stages(c, m)
+// httpAPI sets up the HTTP API resources, using the payload format 2.0.
+func httpAPI(c *Config, m Map) {
+	m["Api"] = Map{
+		"Type": "AWS::ApiGatewayV2::Api",
+		"Properties": Map{
+			"Name":         ref("Name"),
+			"Description":  util.ManagedByUp(c.Description),
+			"ProtocolType": "HTTP",
+		},
+	}
+
+	m["ApiIntegration"] = Map{
+		"Type": "AWS::ApiGatewayV2::Integration",
+		"Properties": Map{
+			"ApiId":                ref("Api"),
+			"IntegrationType":      "AWS_PROXY",
+			"IntegrationUri":       lambdaArnQualifier("FunctionName", stageVariable("qualifier")),
+			"PayloadFormatVersion": "2.0",
+		},
+	}
+
+	m["ApiRoute"] = Map{
+		"Type": "AWS::ApiGatewayV2::Route",
+		"Properties": Map{
+			"ApiId":    ref("Api"),
+			"RouteKey": "$default",
+			"Target":   join("/", "integrations", ref("ApiIntegration")),
+		},
+	}
 }
 
 // stages sets up the stage specific resources.
@@ -190,9 +259,17 @@ func stages(c *Config, m Map) {
 // stage sets up the stage specific resources.
 func stage(c *Config, s *config.Stage, m Map) {
 	aliasID := stageAlias(c, s, m)
+
+	if isFunctionURL(c) {
+		stageFunctionURL(c, s, m, aliasID)
+		stageALB(c, s, m, aliasID)
+		return
+	}
+
 	deploymentID := stageDeployment(c, s, m, aliasID)
 	stagePermissions(c, s, m, aliasID)
 	stageDomain(c, s, m, deploymentID)
+	stageALB(c, s, m, aliasID)
 }
 
 // stageAlias sets up the lambda alias and deployment and returns the alias id.
@@ -243,6 +320,10 @@ func stagePermissions(c *Config, s *config.Stage, m Map, aliasID string) {
 
 // stageDeployment sets up the API Gateway deployment.
 func stageDeployment(c *Config, s *config.Stage, m Map, aliasID string) string {
+	if isHTTPAPI(c) {
+		return stageHTTP(c, s, m, aliasID)
+	}
+
 	id := util.Camelcase("api_deployment_%s", s.Name)
 
 	m[id] = Map{
@@ -262,6 +343,144 @@ func stageDeployment(c *Config, s *config.Stage, m Map, aliasID string) string {
 	return id
 }
 
+// stageHTTP sets up the HTTP API stage, deployed automatically.
+func stageHTTP(c *Config, s *config.Stage, m Map, aliasID string) string {
+	id := util.Camelcase("api_stage_%s", s.Name)
+
+	m[id] = Map{
+		"Type":      "AWS::ApiGatewayV2::Stage",
+		"DependsOn": []string{"ApiRoute", aliasID},
+		"Properties": Map{
+			"ApiId":      ref("Api"),
+			"StageName":  s.Name,
+			"AutoDeploy": true,
+			"StageVariables": Map{
+				"qualifier": s.Name,
+			},
+		},
+	}
+
+	return id
+}
+
+// stageFunctionURL sets up the function url of the stage alias,
+// using the response streaming invoke mode, and its permission.
+func stageFunctionURL(c *Config, s *config.Stage, m Map, aliasID string) {
+	id := util.Camelcase("function_url_%s", s.Name)
+	permissionID := util.Camelcase("function_url_permission_%s", s.Name)
+
+	m[id] = Map{
+		"Type":      "AWS::Lambda::Url",
+		"DependsOn": aliasID,
+		"Properties": Map{
+			"TargetFunctionArn": lambdaArn("FunctionName"),
+			"Qualifier":         s.Name,
+			"AuthType":          "NONE",
+			"InvokeMode":        "RESPONSE_STREAM",
+		},
+	}
+
+	m[permissionID] = Map{
+		"Type":      "AWS::Lambda::Permission",
+		"DependsOn": aliasID,
+		"Properties": Map{
+			"Action":              "lambda:InvokeFunctionUrl",
+			"FunctionName":        lambdaArnQualifier("FunctionName", s.Name),
+			"FunctionUrlAuthType": "NONE",
+			"Principal":           "*",
+		},
+	}
+}
+
+// isALBRegion returns true if the load balancer listener is in the stack region.
+func isALBRegion(c *Config, a *config.ALB) bool {
+	if !isMultiRegion(c) {
+		return true
+	}
+
+	v, err := arn.Parse(a.ListenerArn)
+	return err == nil && v.Region == c.Region
+}
+
+// stageALB sets up the target group and listener rule forwarding
+// requests from an Application Load Balancer to the stage alias.
+func stageALB(c *Config, s *config.Stage, m Map, aliasID string) {
+	if s.ALB == nil || !isALBRegion(c, s.ALB) {
+		return
+	}
+
+	permissionID := util.Camelcase("alb_lambda_permission_%s", s.Name)
+	targetGroupID := util.Camelcase("alb_target_group_%s", s.Name)
+	ruleID := util.Camelcase("alb_listener_rule_%s", s.Name)
+
+	// the target group depends on the permission, so its
+	// arn is unavailable and the account is scoped instead
+	m[permissionID] = Map{
+		"Type":      "AWS::Lambda::Permission",
+		"DependsOn": aliasID,
+		"Properties": Map{
+			"Action":        "lambda:invokeFunction",
+			"FunctionName":  lambdaArnQualifier("FunctionName", s.Name),
+			"Principal":     "elasticloadbalancing.amazonaws.com",
+			"SourceAccount": ref("AWS::AccountId"),
+		},
+	}
+
+	m[targetGroupID] = Map{
+		"Type":      "AWS::ElasticLoadBalancingV2::TargetGroup",
+		"DependsOn": permissionID,
+		"Properties": Map{
+			"TargetType": "lambda",
+			"Targets": []Map{
+				{
+					"Id": lambdaArnQualifier("FunctionName", s.Name),
+				},
+			},
+			"TargetGroupAttributes": []Map{
+				{
+					"Key":   "lambda.multi_value_headers.enabled",
+					"Value": "true",
+				},
+			},
+		},
+	}
+
+	var conditions []Map
+
+	if len(s.ALB.Hosts) > 0 {
+		conditions = append(conditions, Map{
+			"Field": "host-header",
+			"HostHeaderConfig": Map{
+				"Values": s.ALB.Hosts,
+			},
+		})
+	}
+
+	if len(s.ALB.Paths) > 0 {
+		conditions = append(conditions, Map{
+			"Field": "path-pattern",
+			"PathPatternConfig": Map{
+				"Values": s.ALB.Paths,
+			},
+		})
+	}
+
+	m[ruleID] = Map{
+		"Type": "AWS::ElasticLoadBalancingV2::ListenerRule",
+		"Properties": Map{
+			"ListenerArn": s.ALB.ListenerArn,
+			"Priority":    s.ALB.Priority,
+			"Conditions":  conditions,
+			"Actions": []Map{
+				{
+					"Type":           "forward",
+					"TargetGroupArn": ref(targetGroupID),
+				},
+			},
+		},
+	}
+}
+
 // stageDomain sets up a custom domain, dns record and path mapping.
 func stageDomain(c *Config, s *config.Stage, m Map, deploymentID string) {
 	if s.Domain == "" {
@@ -270,12 +489,49 @@ func stageDomain(c *Config, s *config.Stage, m Map, deploymentID string) {
 
 	id := util.Camelcase("api_domain_%s", s.Name)
 
+	props := Map{
+		"CertificateArn": s.Cert,
+		"DomainName":     s.Domain,
+	}
+
+	// HTTP APIs only support regional endpoints
+	if isHTTPAPI(c) {
+		m[id] = Map{
+			"Type": "AWS::ApiGatewayV2::DomainName",
+			"Properties": Map{
+				"DomainName": s.Domain,
+				"DomainNameConfigurations": []Map{
+					{
+						"CertificateArn": s.Cert,
+						"EndpointType":   "REGIONAL",
+					},
+				},
+			},
+		}
+
+		stagePathMapping(c, s, m, deploymentID, id)
+
+		if s.Zone != false {
+			stageDNSRecord(c, s, m, id)
+		}
+
+		return
+	}
+
+	// regional endpoints allow latency-based routing between regions
+	if isMultiRegion(c) {
+		props = Map{
+			"RegionalCertificateArn": s.Cert,
+			"DomainName":             s.Domain,
+			"EndpointConfiguration": Map{
+				"Types": []string{"REGIONAL"},
+			},
+		}
+	}
+
 	m[id] = Map{
-		"Type": "AWS::ApiGateway::DomainName",
-		"Properties": Map{
-			"CertificateArn": s.Cert,
-			"DomainName":     s.Domain,
-		},
+		"Type":       "AWS::ApiGateway::DomainName",
+		"Properties": props,
 	}
 
 	stagePathMapping(c, s, m, deploymentID, id)
@@ -289,6 +545,20 @@ func stageDomain(c *Config, s *config.Stage, m Map, deploymentID string) {
 func stagePathMapping(c *Config, s *config.Stage, m Map, deploymentID, domainID string) {
 	id := util.Camelcase("api_domain_%s_path_mapping", s.Name)
 
+	if isHTTPAPI(c) {
+		m[id] = Map{
+			"Type":      "AWS::ApiGatewayV2::ApiMapping",
+			"DependsOn": []string{deploymentID, domainID},
+			"Properties": Map{
+				"DomainName":    s.Domain,
+				"ApiMappingKey": util.BasePath(s.Path),
+				"ApiId":         ref("Api"),
+				"Stage":         s.Name,
+			},
+		}
+		return
+	}
+
 	m[id] = Map{
 		"Type":      "AWS::ApiGateway::BasePathMapping",
 		"DependsOn": []string{deploymentID, domainID},
@@ -311,25 +581,51 @@ func stageDNSRecord(c *Config, s *config.Stage, m Map, domainID string) {
 		zoneName = s
 	}
 
-	zone := dnsZone(c, m, zoneName)
+	props := Map{
+		"Name":    s.Domain,
+		"Type":    "A",
+		"Comment": util.ManagedByUp(""),
+		"AliasTarget": Map{
+			"DNSName":      get(domainID, "DistributionDomainName"),
+			"HostedZoneId": "Z2FDTNDATAQYW2",
+		},
+	}
+
+	// secondary regions never create the zone, they
+	// reference the one created by the primary region
+	if isPrimaryRegion(c) || getZone(c, zoneName) != nil {
+		props["HostedZoneId"] = dnsZone(c, m, zoneName)
+	} else {
+		props["HostedZoneName"] = zoneName + "."
+	}
+
+	// latency-based record per region
+	if isMultiRegion(c) {
+		props["Region"] = c.Region
+		props["SetIdentifier"] = c.Region
+	}
+
+	// regional endpoint
+	if isMultiRegion(c) || isHTTPAPI(c) {
+		props["AliasTarget"] = Map{
+			"DNSName":      get(domainID, "RegionalDomainName"),
+			"HostedZoneId": get(domainID, "RegionalHostedZoneId"),
+		}
+	}
 
 	m[id] = Map{
-		"Type": "AWS::Route53::RecordSet",
-		"Properties": Map{
-			"Name":         s.Domain,
-			"Type":         "A",
-			"Comment":      util.ManagedByUp(""),
-			"HostedZoneId": zone,
-			"AliasTarget": Map{
-				"DNSName":      get(domainID, "DistributionDomainName"),
-				"HostedZoneId": "Z2FDTNDATAQYW2",
-			},
-		},
+		"Type":       "AWS::Route53::RecordSet",
+		"Properties": props,
 	}
 }
 
 // dns setups the the user-defined DNS zones and records.
 func dns(c *Config, m Map) {
+	// global, so only managed by the primary region
+	if !isPrimaryRegion(c) {
+		return
+	}
+
 	for _, z := range c.DNS.Zones {
 		zone := dnsZone(c, m, z.Name)
 
@@ -351,11 +647,85 @@ func dns(c *Config, m Map) {
 	}
 }
 
+// crons sets up the rules invoking the stage aliases on a schedule,
+// in the primary region only so that each is invoked once.
+func crons(c *Config, m Map) {
+	if !isPrimaryRegion(c) {
+		return
+	}
+
+	for _, v := range c.Crons {
+		cron(c, v, m)
+	}
+}
+
+// jsonString is a map marshaled as a JSON string, such as the input of a
+// rule, errors are reported when the template itself is marshaled.
+type jsonString Map
+
+// MarshalJSON implementation.
+func (m jsonString) MarshalJSON() ([]byte, error) {
+	b, err := json.Marshal(Map(m))
+	if err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(string(b))
+}
+
+// cron sets up the EventBridge rule and lambda:invokeFunction permission of a
+// cron. The rule's input is a scheduled event providing the request to make.
+func cron(c *Config, v config.Cron, m Map) {
+	id := util.Camelcase("cron_%s", v.Name)
+	permissionID := util.Camelcase("cron_%s_permission", v.Name)
+	aliasID := util.Camelcase("api_function_alias_%s", v.Stage)
+
+	input := jsonString{
+		"source":      "up.cron",
+		"detail-type": "Scheduled Event",
+		"detail": Map{
+			"name":   v.Name,
+			"method": v.Method,
+			"path":   v.Path,
+			"body":   v.Body,
+		},
+	}
+
+	m[id] = Map{
+		"Type":      "AWS::Events::Rule",
+		"DependsOn": aliasID,
+		"Properties": Map{
+			"Description":        util.ManagedByUp(fmt.Sprintf("Cron %s", v.Name)),
+			"ScheduleExpression": v.Schedule,
+			"State":              "ENABLED",
+			"Targets": []Map{
+				{
+					"Id":    v.Name,
+					"Arn":   lambdaArnQualifier("FunctionName", v.Stage),
+					"Input": input,
+				},
+			},
+		},
+	}
+
+	m[permissionID] = Map{
+		"Type":      "AWS::Lambda::Permission",
+		"DependsOn": aliasID,
+		"Properties": Map{
+			"Action":       "lambda:invokeFunction",
+			"FunctionName": lambdaArnQualifier("FunctionName", v.Stage),
+			"Principal":    "events.amazonaws.com",
+			"SourceArn":    get(id, "Arn"),
+		},
+	}
+}
+
 // resources of the stack.
 func resources(c *Config) Map {
 	m := Map{}
 	api(c, m)
 	dns(c, m)
+	crons(c, m)
 	return m
 }
 
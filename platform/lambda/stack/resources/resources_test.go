@@ -376,6 +376,275 @@ func Example_stageDeployment() {
 	// }
 }
 
+func Example_httpAPIIntegration() {
+	c := &Config{
+		Config: &up.Config{
+			Name: "polls",
+			API: config.API{
+				Type: "http",
+			},
+		},
+	}
+
+	dump(c, "ApiIntegration")
+	// Output:
+	// {
+	//   "Properties": {
+	//     "ApiId": {
+	//       "Ref": "Api"
+	//     },
+	//     "IntegrationType": "AWS_PROXY",
+	//     "IntegrationUri": {
+	//       "Fn::Join": [
+	//         ":",
+	//         [
+	//           "arn",
+	//           "aws",
+	//           "lambda",
+	//           {
+	//             "Ref": "AWS::Region"
+	//           },
+	//           {
+	//             "Ref": "AWS::AccountId"
+	//           },
+	//           "function",
+	//           {
+	//             "Fn::Join": [
+	//               ":",
+	//               [
+	//                 {
+	//                   "Ref": "FunctionName"
+	//                 },
+	//                 "${stageVariables.qualifier}"
+	//               ]
+	//             ]
+	//           }
+	//         ]
+	//       ]
+	//     },
+	//     "PayloadFormatVersion": "2.0"
+	//   },
+	//   "Type": "AWS::ApiGatewayV2::Integration"
+	// }
+}
+
+func Example_httpAPIStage() {
+	c := &Config{
+		Config: &up.Config{
+			Name: "polls",
+			API: config.API{
+				Type: "http",
+			},
+			Stages: config.Stages{
+				"production": &config.Stage{
+					Name: "production",
+				},
+			},
+		},
+		Versions: Versions{
+			"production": "15",
+		},
+	}
+
+	dump(c, "ApiStageProduction")
+	// Output:
+	// {
+	//   "DependsOn": [
+	//     "ApiRoute",
+	//     "ApiFunctionAliasProduction"
+	//   ],
+	//   "Properties": {
+	//     "ApiId": {
+	//       "Ref": "Api"
+	//     },
+	//     "AutoDeploy": true,
+	//     "StageName": "production",
+	//     "StageVariables": {
+	//       "qualifier": "production"
+	//     }
+	//   },
+	//   "Type": "AWS::ApiGatewayV2::Stage"
+	// }
+}
+
+func Example_stageFunctionURL() {
+	c := &Config{
+		Config: &up.Config{
+			Name: "polls",
+			API: config.API{
+				Type: "url",
+			},
+			Stages: config.Stages{
+				"production": &config.Stage{
+					Name: "production",
+				},
+			},
+		},
+		Versions: Versions{
+			"production": "15",
+		},
+	}
+
+	dump(c, "FunctionUrlProduction")
+	// Output:
+	// {
+	//   "DependsOn": "ApiFunctionAliasProduction",
+	//   "Properties": {
+	//     "AuthType": "NONE",
+	//     "InvokeMode": "RESPONSE_STREAM",
+	//     "Qualifier": "production",
+	//     "TargetFunctionArn": {
+	//       "Fn::Join": [
+	//         ":",
+	//         [
+	//           "arn",
+	//           "aws",
+	//           "lambda",
+	//           {
+	//             "Ref": "AWS::Region"
+	//           },
+	//           {
+	//             "Ref": "AWS::AccountId"
+	//           },
+	//           "function",
+	//           {
+	//             "Ref": "FunctionName"
+	//           }
+	//         ]
+	//       ]
+	//     }
+	//   },
+	//   "Type": "AWS::Lambda::Url"
+	// }
+}
+
+func Example_stageALBTargetGroup() {
+	c := &Config{
+		Config: &up.Config{
+			Name: "polls",
+			Stages: config.Stages{
+				"production": &config.Stage{
+					Name: "production",
+					ALB: &config.ALB{
+						ListenerArn: "arn:aws:elasticloadbalancing:us-west-2:111111111:listener/app/internal/50dc6c495c0c9188/f2f7dc8efc522ab2",
+						Priority:    10,
+						Paths:       []string{"/polls/*"},
+					},
+				},
+			},
+		},
+		Versions: Versions{
+			"production": "15",
+		},
+	}
+
+	dump(c, "AlbTargetGroupProduction")
+	// Output:
+	// {
+	//   "DependsOn": "AlbLambdaPermissionProduction",
+	//   "Properties": {
+	//     "TargetGroupAttributes": [
+	//       {
+	//         "Key": "lambda.multi_value_headers.enabled",
+	//         "Value": "true"
+	//       }
+	//     ],
+	//     "TargetType": "lambda",
+	//     "Targets": [
+	//       {
+	//         "Id": {
+	//           "Fn::Join": [
+	//             ":",
+	//             [
+	//               "arn",
+	//               "aws",
+	//               "lambda",
+	//               {
+	//                 "Ref": "AWS::Region"
+	//               },
+	//               {
+	//                 "Ref": "AWS::AccountId"
+	//               },
+	//               "function",
+	//               {
+	//                 "Fn::Join": [
+	//                   ":",
+	//                   [
+	//                     {
+	//                       "Ref": "FunctionName"
+	//                     },
+	//                     "production"
+	//                   ]
+	//                 ]
+	//               }
+	//             ]
+	//           ]
+	//         }
+	//       }
+	//     ]
+	//   },
+	//   "Type": "AWS::ElasticLoadBalancingV2::TargetGroup"
+	// }
+}
+
+func Example_stageALBListenerRule() {
+	c := &Config{
+		Config: &up.Config{
+			Name: "polls",
+			Stages: config.Stages{
+				"production": &config.Stage{
+					Name: "production",
+					ALB: &config.ALB{
+						ListenerArn: "arn:aws:elasticloadbalancing:us-west-2:111111111:listener/app/internal/50dc6c495c0c9188/f2f7dc8efc522ab2",
+						Priority:    10,
+						Hosts:       []string{"polls.internal"},
+						Paths:       []string{"/polls/*"},
+					},
+				},
+			},
+		},
+		Versions: Versions{
+			"production": "15",
+		},
+	}
+
+	dump(c, "AlbListenerRuleProduction")
+	// Output:
+	// {
+	//   "Properties": {
+	//     "Actions": [
+	//       {
+	//         "TargetGroupArn": {
+	//           "Ref": "AlbTargetGroupProduction"
+	//         },
+	//         "Type": "forward"
+	//       }
+	//     ],
+	//     "Conditions": [
+	//       {
+	//         "Field": "host-header",
+	//         "HostHeaderConfig": {
+	//           "Values": [
+	//             "polls.internal"
+	//           ]
+	//         }
+	//       },
+	//       {
+	//         "Field": "path-pattern",
+	//         "PathPatternConfig": {
+	//           "Values": [
+	//             "/polls/*"
+	//           ]
+	//         }
+	//       }
+	//     ],
+	//     "ListenerArn": "arn:aws:elasticloadbalancing:us-west-2:111111111:listener/app/internal/50dc6c495c0c9188/f2f7dc8efc522ab2",
+	//     "Priority": 10
+	//   },
+	//   "Type": "AWS::ElasticLoadBalancingV2::ListenerRule"
+	// }
+}
+
 func Example_stageDomain() {
 	c := &Config{
 		Config: &up.Config{
@@ -507,6 +776,88 @@ func Example_stageDNSZoneRecord() {
 	// }
 }
 
+func Example_stageDomainMultiRegion() {
+	c := &Config{
+		Config: &up.Config{
+			Name:    "polls",
+			Regions: []string{"us-west-2", "eu-west-1"},
+			Stages: config.Stages{
+				"production": &config.Stage{
+					Name:   "production",
+					Domain: "up-example.com",
+					Cert:   "arn::something",
+				},
+			},
+		},
+		Versions: Versions{
+			"production": "15",
+		},
+		Region: "eu-west-1",
+	}
+
+	dump(c, "ApiDomainProduction")
+	// Output:
+	// {
+	//   "Properties": {
+	//     "DomainName": "up-example.com",
+	//     "EndpointConfiguration": {
+	//       "Types": [
+	//         "REGIONAL"
+	//       ]
+	//     },
+	//     "RegionalCertificateArn": "arn::something"
+	//   },
+	//   "Type": "AWS::ApiGateway::DomainName"
+	// }
+}
+
+func Example_stageDNSZoneRecordMultiRegion() {
+	c := &Config{
+		Config: &up.Config{
+			Name:    "polls",
+			Regions: []string{"us-west-2", "eu-west-1"},
+			Stages: config.Stages{
+				"production": &config.Stage{
+					Name:   "production",
+					Domain: "up-example.com",
+				},
+			},
+		},
+		Versions: Versions{
+			"production": "15",
+		},
+		Region: "eu-west-1",
+	}
+
+	dump(c, "DnsZoneUpExampleComRecordUpExampleCom")
+	// Output:
+	// {
+	//   "Properties": {
+	//     "AliasTarget": {
+	//       "DNSName": {
+	//         "Fn::GetAtt": [
+	//           "ApiDomainProduction",
+	//           "RegionalDomainName"
+	//         ]
+	//       },
+	//       "HostedZoneId": {
+	//         "Fn::GetAtt": [
+	//           "ApiDomainProduction",
+	//           "RegionalHostedZoneId"
+	//         ]
+	//       }
+	//     },
+	//     "Comment": "Managed by Up.",
+	//     "HostedZoneName": "up-example.com.",
+	//     "Name": "up-example.com",
+	//     "Region": "eu-west-1",
+	//     "SetIdentifier": "eu-west-1",
+	//     "Type": "A"
+	//   },
+	//   "Type": "AWS::Route53::RecordSet"
+	// }
+}
+
 func Example_dnsZone() {
 	c := &Config{
 		Config: &up.Config{
@@ -573,3 +924,74 @@ func Example_dnsZoneRecord() {
 	//   "Type": "AWS::Route53::RecordSet"
 	// }
 }
+
+func Example_cron() {
+	c := &Config{
+		Config: &up.Config{
+			Name: "polls",
+			Stages: config.Stages{
+				"production": &config.Stage{
+					Name: "production",
+				},
+			},
+			Crons: config.Crons{
+				{
+					Name:     "cleanup",
+					Schedule: "rate(1 hour)",
+					Method:   "POST",
+					Path:     "/tasks/cleanup",
+					Stage:    "production",
+				},
+			},
+		},
+		Versions: Versions{
+			"production": "15",
+		},
+	}
+
+	dump(c, "CronCleanup")
+	// Output:
+	// {
+	//   "DependsOn": "ApiFunctionAliasProduction",
+	//   "Properties": {
+	//     "Description": "Cron cleanup (Managed by Up).",
+	//     "ScheduleExpression": "rate(1 hour)",
+	//     "State": "ENABLED",
+	//     "Targets": [
+	//       {
+	//         "Arn": {
+	//           "Fn::Join": [
+	//             ":",
+	//             [
+	//               "arn",
+	//               "aws",
+	//               "lambda",
+	//               {
+	//                 "Ref": "AWS::Region"
+	//               },
+	//               {
+	//                 "Ref": "AWS::AccountId"
+	//               },
+	//               "function",
+	//               {
+	//                 "Fn::Join": [
+	//                   ":",
+	//                   [
+	//                     {
+	//                       "Ref": "FunctionName"
+	//                     },
+	//                     "production"
+	//                   ]
+	//                 ]
+	//               }
+	//             ]
+	//           ]
+	//         },
+	//         "Id": "cleanup",
+	//         "Input": "{\"detail\":{\"body\":\"\",\"method\":\"POST\",\"name\":\"cleanup\",\"path\":\"/tasks/cleanup\"},\"detail-type\":\"Scheduled Event\",\"source\":\"up.cron\"}"
+	//       }
+	//     ]
+	//   },
+	//   "Type": "AWS::Events::Rule"
+	// }
+}
@@ -7,7 +7,6 @@ import (
 	"time"
 
 	"github.com/aws/aws-sdk-go/aws"
-	"github.com/aws/aws-sdk-go/aws/session"
 	"github.com/aws/aws-sdk-go/service/apigateway"
 	"github.com/aws/aws-sdk-go/service/cloudformation"
 	"github.com/aws/aws-sdk-go/service/lambda"
@@ -41,12 +40,13 @@ type Stack struct {
 	apigateway *apigateway.APIGateway
 	events     event.Events
 	zones      []*route53.HostedZone
+	region     string
 	config     *up.Config
 }
 
 // New stack.
 func New(c *up.Config, events event.Events, zones []*route53.HostedZone, region string) *Stack {
-	sess := session.New(aws.NewConfig().WithRegion(region))
+	sess := c.Session(region)
 	return &Stack{
 		client:     cloudformation.New(sess),
 		lambda:     lambda.New(sess),
@@ -54,6 +54,7 @@ func New(c *up.Config, events event.Events, zones []*route53.HostedZone, region
 		apigateway: apigateway.New(sess),
 		events:     events,
 		zones:      zones,
+		region:     region,
 		config:     c,
 	}
 }
@@ -64,6 +65,7 @@ func (s *Stack) template(versions resources.Versions) Map {
 		Config:   s.config,
 		Zones:    s.zones,
 		Versions: versions,
+		Region:   s.region,
 	})
 }
 
@@ -61,6 +61,8 @@ func (r *reporter) Start() {
 				s = "version " + v
 			}
 			r.complete("deploy", s, e.Duration("duration"))
+		case "platform.deploy.error":
+			r.error(e.String("region"), e.String("error"))
 		}
 	}
 }
@@ -139,6 +139,8 @@ func (r *reporter) Start() {
 				r.complete("deploy", s, e.Duration("duration"))
 			case "platform.deploy.url":
 				r.log("endpoint", e.String("url"))
+			case "platform.deploy.error":
+				r.error(e.String("region"), e.String("error"))
 			case "platform.function.create":
 				r.inlineProgress = true
 			case "stack.create":
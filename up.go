@@ -20,6 +20,13 @@ type Config = config.Config
 // ReadConfig reads the configuration from `path`.
 var ReadConfig = config.ReadConfig
 
+// ReadDeployedConfig reads the configuration from `path`,
+// resolving variables from the values resolved when deploying.
+var ReadDeployedConfig = config.ReadDeployedConfig
+
+// FindConfig returns the path of the configuration file.
+var FindConfig = config.FindConfig
+
 // ParseConfigString returns config from JSON string.
 var ParseConfigString = config.ParseConfigString
 
@@ -61,6 +68,12 @@ func (p *Project) RunHook(name string) error {
 		"hook": hook,
 	})()
 
+	// the stage's credentials for commands such as the aws cli
+	creds, err := p.config.Credentials.Env()
+	if err != nil {
+		return errors.Wrap(err, "credentials")
+	}
+
 	for _, command := range hook {
 		log.Debugf("hook %q command %q", name, command)
 
@@ -68,6 +81,7 @@ func (p *Project) RunHook(name string) error {
 		cmd.Env = os.Environ()
 		cmd.Env = append(cmd.Env, util.Env(p.config.Environment)...)
 		cmd.Env = append(cmd.Env, "PATH=node_modules/.bin:"+os.Getenv("PATH"))
+		cmd.Env = append(cmd.Env, creds...)
 
 		b, err := cmd.CombinedOutput()
 		if err != nil {